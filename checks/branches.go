@@ -0,0 +1,150 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register(branchProtectionCheck{})
+}
+
+// branchRule says what may be done to protected branches.
+type branchRule struct {
+	// Branches are globs of the protected branch names.
+	Branches []string `yaml:"branches"`
+	// AllowPush allows pushing to protected branches at all; AllowForcePush
+	// and AllowDelete allow rewriting and deleting them.
+	AllowPush      *bool `yaml:"allowPush"`
+	AllowForcePush *bool `yaml:"allowForcePush"`
+	AllowDelete    *bool `yaml:"allowDelete"`
+}
+
+// branchProtectionOptions are the options of the branch-protection check.
+type branchProtectionOptions struct {
+	branchRule `yaml:",inline"`
+	// AllowCommit allows committing directly on protected branches.
+	AllowCommit bool `yaml:"allowCommit"`
+	// Remotes override the rule for pushes to the remotes they name.
+	Remotes map[string]branchRule `yaml:"remotes"`
+}
+
+// branchPolicy is a branchRule with its globs compiled and defaults applied.
+type branchPolicy struct {
+	branches       []*Glob
+	allowPush      bool
+	allowForcePush bool
+	allowDelete    bool
+}
+
+type branchProtectionCheck struct{}
+
+func (branchProtectionCheck) Name() string { return "branch-protection" }
+
+func (branchProtectionCheck) Description() string {
+	return "Blocks direct commits to protected branches and force-pushes or deletions of them"
+}
+
+func (branchProtectionCheck) HookTypes() []string { return []string{"pre-commit", "pre-push"} }
+
+func (branchProtectionCheck) ValidateOptions(opts Options) error {
+	options, err := decodeBranchProtection(opts)
+	if err != nil {
+		return err
+	}
+	if _, err := newBranchPolicy(options.branchRule, branchPolicy{}); err != nil {
+		return err
+	}
+	for remote, rule := range options.Remotes {
+		if _, err := newBranchPolicy(rule, branchPolicy{}); err != nil {
+			return fmt.Errorf("remote %s: %w", remote, err)
+		}
+	}
+	return nil
+}
+
+func (branchProtectionCheck) Run(ctx *Context) ([]Finding, error) {
+	options, err := decodeBranchProtection(ctx.Options)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := newBranchPolicy(options.branchRule, branchPolicy{allowPush: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.HookType == "pre-commit" {
+		branch, unborn := currentBranch(ctx)
+		// The first commit of a new repository has to go somewhere
+		if options.AllowCommit || unborn || branch == "" || !matchAny(defaults.branches, branch) {
+			return nil, nil
+		}
+		return []Finding{{
+			Rule:    "commit",
+			Message: fmt.Sprintf("direct commits to %s are not allowed; commit on another branch and open a pull request", branch),
+		}}, nil
+	}
+
+	policy := defaults
+	if rule, ok := options.Remotes[ctx.Remote]; ok {
+		if policy, err = newBranchPolicy(rule, defaults); err != nil {
+			return nil, err
+		}
+	}
+	var findings []Finding
+	for _, update := range ctx.Updates {
+		branch, ok := strings.CutPrefix(update.RemoteRef, "refs/heads/")
+		if !ok || !matchAny(policy.branches, branch) {
+			continue
+		}
+		target := branch
+		if ctx.Remote != "" {
+			target = ctx.Remote + "/" + branch
+		}
+		switch {
+		case update.IsDelete():
+			if !policy.allowDelete {
+				findings = append(findings, Finding{Rule: "delete", Message: fmt.Sprintf("deleting %s is not allowed", target)})
+			}
+		case !policy.allowPush:
+			findings = append(findings, Finding{Rule: "push", Message: fmt.Sprintf("pushing directly to %s is not allowed; push another branch and open a pull request", target)})
+		case !update.IsNew() && !policy.allowForcePush:
+			fastForward, err := isAncestor(ctx, update.RemoteSHA, update.LocalSHA)
+			if err != nil {
+				return nil, err
+			}
+			if !fastForward {
+				findings = append(findings, Finding{Rule: "force-push", Message: fmt.Sprintf("force-pushing to %s is not allowed; pull and merge or rebase onto it instead", target)})
+			}
+		}
+	}
+	return findings, nil
+}
+
+func decodeBranchProtection(opts Options) (branchProtectionOptions, error) {
+	options := branchProtectionOptions{branchRule: branchRule{Branches: []string{"main", "master"}}}
+	err := opts.Decode(&options)
+	return options, err
+}
+
+// newBranchPolicy applies rule on top of base, keeping what rule leaves unset.
+func newBranchPolicy(rule branchRule, base branchPolicy) (branchPolicy, error) {
+	policy := base
+	if rule.Branches != nil {
+		branches, err := compileRefGlobs("branches", rule.Branches)
+		if err != nil {
+			return branchPolicy{}, err
+		}
+		policy.branches = branches
+	}
+	if rule.AllowPush != nil {
+		policy.allowPush = *rule.AllowPush
+	}
+	if rule.AllowForcePush != nil {
+		policy.allowForcePush = *rule.AllowForcePush
+	}
+	if rule.AllowDelete != nil {
+		policy.allowDelete = *rule.AllowDelete
+	}
+	return policy, nil
+}
@@ -0,0 +1,146 @@
+package checks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewBranchPolicyLayersRemoteRules(t *testing.T) {
+	options, err := decodeBranchProtection(Options{
+		"allowDelete": true,
+		"remotes": map[string]any{
+			"origin": map[string]any{"allowPush": false},
+			"fork":   map[string]any{"branches": []any{"release/*"}, "allowForcePush": true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults, err := newBranchPolicy(options.branchRule, branchPolicy{allowPush: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		remote                                 string
+		protected, unprotected                 string
+		allowPush, allowForcePush, allowDelete bool
+	}{
+		{remote: "", protected: "main", unprotected: "release/1.0", allowPush: true, allowDelete: true},
+		{remote: "origin", protected: "master", unprotected: "release/1.0", allowDelete: true},
+		{remote: "fork", protected: "release/1.0", unprotected: "main", allowPush: true, allowForcePush: true, allowDelete: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			policy := defaults
+			if rule, ok := options.Remotes[tt.remote]; ok {
+				if policy, err = newBranchPolicy(rule, defaults); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !matchAny(policy.branches, tt.protected) || matchAny(policy.branches, tt.unprotected) {
+				t.Errorf("branches protect %s = %v and %s = %v, want true and false", tt.protected, matchAny(policy.branches, tt.protected), tt.unprotected, matchAny(policy.branches, tt.unprotected))
+			}
+			got := []bool{policy.allowPush, policy.allowForcePush, policy.allowDelete}
+			if want := []bool{tt.allowPush, tt.allowForcePush, tt.allowDelete}; !reflect.DeepEqual(got, want) {
+				t.Errorf("allowPush, allowForcePush, allowDelete = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBranchProtectionPush(t *testing.T) {
+	dir := newTestRepo(t, map[string]string{"a.txt": "one\n"})
+	runGit(t, dir, "commit", "--quiet", "-m", "first")
+	base := runGit(t, dir, "rev-parse", "HEAD")
+	runGit(t, dir, "commit", "--quiet", "--allow-empty", "-m", "second")
+	ahead := runGit(t, dir, "rev-parse", "HEAD")
+	runGit(t, dir, "checkout", "--quiet", "--detach", base)
+	runGit(t, dir, "commit", "--quiet", "--allow-empty", "-m", "diverged")
+	diverged := runGit(t, dir, "rev-parse", "HEAD")
+	missing := strings.Repeat("0", 40)
+
+	tests := []struct {
+		name    string
+		remote  string
+		opts    Options
+		updates []RefUpdate
+		want    []string
+	}{
+		{
+			name:    "fast-forward",
+			updates: []RefUpdate{{LocalRef: "refs/heads/main", LocalSHA: ahead, RemoteRef: "refs/heads/main", RemoteSHA: base}},
+		},
+		{
+			name:    "non-fast-forward",
+			remote:  "origin",
+			updates: []RefUpdate{{LocalRef: "refs/heads/main", LocalSHA: diverged, RemoteRef: "refs/heads/main", RemoteSHA: ahead}},
+			want:    []string{"force-push: force-pushing to origin/main is not allowed; pull and merge or rebase onto it instead"},
+		},
+		{
+			name:    "force-push allowed",
+			opts:    Options{"allowForcePush": true},
+			updates: []RefUpdate{{LocalRef: "refs/heads/main", LocalSHA: diverged, RemoteRef: "refs/heads/main", RemoteSHA: ahead}},
+		},
+		{
+			name:    "remote ref missing locally",
+			updates: []RefUpdate{{LocalRef: "refs/heads/main", LocalSHA: ahead, RemoteRef: "refs/heads/main", RemoteSHA: strings.Repeat("1", 40)}},
+			want:    []string{"force-push: force-pushing to main is not allowed; pull and merge or rebase onto it instead"},
+		},
+		{
+			name:    "unprotected branch",
+			updates: []RefUpdate{{LocalRef: "refs/heads/feature", LocalSHA: diverged, RemoteRef: "refs/heads/feature", RemoteSHA: ahead}},
+		},
+		{
+			name:    "new ref",
+			updates: []RefUpdate{{LocalRef: "refs/heads/main", LocalSHA: diverged, RemoteRef: "refs/heads/main", RemoteSHA: missing}},
+		},
+		{
+			name:    "delete",
+			remote:  "origin",
+			updates: []RefUpdate{{LocalRef: "(delete)", LocalSHA: missing, RemoteRef: "refs/heads/master", RemoteSHA: base}},
+			want:    []string{"delete: deleting origin/master is not allowed"},
+		},
+		{
+			name:    "delete allowed",
+			opts:    Options{"allowDelete": true},
+			updates: []RefUpdate{{LocalRef: "(delete)", LocalSHA: missing, RemoteRef: "refs/heads/master", RemoteSHA: base}},
+		},
+		{
+			name:   "push not allowed to the remote",
+			remote: "upstream",
+			opts:   Options{"remotes": map[string]any{"upstream": map[string]any{"allowPush": false}}},
+			updates: []RefUpdate{
+				{LocalRef: "refs/heads/main", LocalSHA: ahead, RemoteRef: "refs/heads/main", RemoteSHA: base},
+				{LocalRef: "refs/heads/main", LocalSHA: diverged, RemoteRef: "refs/heads/main", RemoteSHA: missing},
+			},
+			want: []string{
+				"push: pushing directly to upstream/main is not allowed; push another branch and open a pull request",
+				"push: pushing directly to upstream/main is not allowed; push another branch and open a pull request",
+			},
+		},
+		{
+			name:    "push allowed to other remotes",
+			remote:  "origin",
+			opts:    Options{"remotes": map[string]any{"upstream": map[string]any{"allowPush": false}}},
+			updates: []RefUpdate{{LocalRef: "refs/heads/main", LocalSHA: ahead, RemoteRef: "refs/heads/main", RemoteSHA: base}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &Context{HookType: "pre-push", RepoRoot: dir, Remote: tt.remote, Options: tt.opts, Updates: tt.updates}
+			findings, err := branchProtectionCheck{}.Run(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, finding := range findings {
+				got = append(got, finding.Rule+": "+finding.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
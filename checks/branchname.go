@@ -0,0 +1,183 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+func init() {
+	Register(branchNameCheck{})
+}
+
+var (
+	// Characters that do not belong in branch names, replaced by a dash
+	// in suggestions.
+	branchNameInvalid = regexp.MustCompile(`[^A-Za-z0-9/._-]+|_`)
+	branchNameDashes  = regexp.MustCompile(`-{2,}`)
+)
+
+// branchTypeAliases map common branch prefixes to the type usually meant.
+var branchTypeAliases = map[string]string{
+	"feature": "feat", "features": "feat", "bugfix": "fix", "bug": "fix", "hotfix": "fix", "doc": "docs",
+}
+
+// branchNameOptions are the options of the branch-name check.
+type branchNameOptions struct {
+	// Patterns are regular expressions; branch names must match one.
+	Patterns []string `yaml:"patterns"`
+	// Exempt lists globs of branch names that are always allowed.
+	Exempt []string `yaml:"exempt"`
+	// Types are the prefixes tried when suggesting a better name.
+	Types []string `yaml:"types"`
+	// Example is a valid name shown when a name is rejected.
+	Example string `yaml:"example"`
+}
+
+// branchNamePolicy is the branch-name check configured by its options.
+type branchNamePolicy struct {
+	patterns []*regexp.Regexp
+	exempt   []*Glob
+	types    []string
+	example  string
+}
+
+type branchNameCheck struct{}
+
+func (branchNameCheck) Name() string { return "branch-name" }
+
+func (branchNameCheck) Description() string {
+	return "Checks the names of pushed branches against patterns, suggesting valid ones"
+}
+
+func (branchNameCheck) HookTypes() []string { return []string{"pre-push", "post-checkout"} }
+
+func (branchNameCheck) ValidateOptions(opts Options) error {
+	_, err := newBranchNamePolicy(opts)
+	return err
+}
+
+func (branchNameCheck) Run(ctx *Context) ([]Finding, error) {
+	policy, err := newBranchNamePolicy(ctx.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	// After a checkout git cannot be stopped any more, so the name is only
+	// pointed out
+	if ctx.HookType == "post-checkout" {
+		// The last argument is 0 when files rather than a branch were
+		// checked out
+		if n := len(ctx.Args); n >= 3 && ctx.Args[n-1] == "0" {
+			return nil, nil
+		}
+		branch, _ := currentBranch(ctx)
+		if branch == "" || policy.allowed(branch) {
+			return nil, nil
+		}
+		finding := policy.reject(branch)
+		finding.Severity = SeverityWarning
+		finding.Message += "; rename it with git branch -m before pushing"
+		return []Finding{finding}, nil
+	}
+
+	var findings []Finding
+	for _, update := range ctx.Updates {
+		branch, ok := strings.CutPrefix(update.RemoteRef, "refs/heads/")
+		// Only new branches are named now; existing ones were accepted
+		// when first pushed or predate the policy
+		if !ok || update.IsDelete() || !update.IsNew() || policy.allowed(branch) {
+			continue
+		}
+		findings = append(findings, policy.reject(branch))
+	}
+	return findings, nil
+}
+
+func newBranchNamePolicy(opts Options) (*branchNamePolicy, error) {
+	options := branchNameOptions{
+		Patterns: []string{`^(feat|fix|chore|docs|refactor|test|ci|build|perf)/[a-z0-9][a-z0-9._-]*$`},
+		Exempt:   []string{"main", "master", "develop", "release/*"},
+		Types:    []string{"feat", "fix", "chore", "docs", "refactor", "test", "ci", "build", "perf"},
+	}
+	if err := opts.Decode(&options); err != nil {
+		return nil, err
+	}
+	policy := &branchNamePolicy{types: options.Types, example: options.Example}
+	for _, pattern := range options.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		policy.patterns = append(policy.patterns, re)
+	}
+	exempt, err := compileRefGlobs("exempt", options.Exempt)
+	if err != nil {
+		return nil, err
+	}
+	policy.exempt = exempt
+	return policy, nil
+}
+
+func (p *branchNamePolicy) allowed(branch string) bool {
+	if matchAny(p.exempt, branch) {
+		return true
+	}
+	for _, re := range p.patterns {
+		if re.MatchString(branch) {
+			return true
+		}
+	}
+	return false
+}
+
+// reject explains why branch is not allowed and what it could be called.
+func (p *branchNamePolicy) reject(branch string) Finding {
+	patterns := make([]string, 0, len(p.patterns))
+	for _, re := range p.patterns {
+		patterns = append(patterns, re.String())
+	}
+	message := fmt.Sprintf("branch name %q does not match %s", branch, strings.Join(patterns, " or "))
+	if suggestions := p.suggest(branch); len(suggestions) > 0 {
+		message += fmt.Sprintf("; try %q", suggestions[0])
+		for _, suggestion := range suggestions[1:] {
+			message += fmt.Sprintf(" or %q", suggestion)
+		}
+	} else if p.example != "" {
+		message += fmt.Sprintf(", such as %q", p.example)
+	}
+	return Finding{Rule: "branch-name", Message: message}
+}
+
+// suggest returns up to three names close to branch that are allowed: the
+// name with invalid characters replaced, lower cased, or with its prefix
+// replaced by or prepended with one of the types.
+func (p *branchNamePolicy) suggest(branch string) []string {
+	clean := strings.Trim(branchNameDashes.ReplaceAllString(branchNameInvalid.ReplaceAllString(branch, "-"), "-"), "-/")
+	prefix, rest, hasPrefix := strings.Cut(clean, "/")
+
+	candidates := []string{clean, strings.ToLower(clean)}
+	if hasPrefix {
+		if alias, ok := branchTypeAliases[strings.ToLower(prefix)]; ok {
+			candidates = append(candidates, alias+"/"+rest, alias+"/"+strings.ToLower(rest))
+		}
+	}
+	for _, t := range p.types {
+		candidates = append(candidates, t+"/"+clean, t+"/"+strings.ToLower(clean))
+		if hasPrefix {
+			candidates = append(candidates, t+"/"+rest, t+"/"+strings.ToLower(rest))
+		}
+	}
+
+	var suggestions []string
+	for _, candidate := range candidates {
+		if candidate != branch && !slices.Contains(suggestions, candidate) && p.allowed(candidate) {
+			suggestions = append(suggestions, candidate)
+		}
+		if len(suggestions) == 3 {
+			break
+		}
+	}
+	return suggestions
+}
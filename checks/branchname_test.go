@@ -0,0 +1,60 @@
+package checks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBranchNamePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		branch  string
+		allowed bool
+		suggest []string
+	}{
+		{name: "valid", branch: "feat/login-page", allowed: true},
+		{name: "exempt", branch: "main", allowed: true},
+		{name: "exempt glob", branch: "release/1.2", allowed: true},
+		{name: "exempt is not a base name", branch: "old/main", suggest: []string{"feat/main", "fix/main", "chore/main"}},
+		{name: "alias prefix", branch: "feature/Login_Page", suggest: []string{"feat/login-page", "fix/login-page", "chore/login-page"}},
+		{name: "upper case", branch: "fix/JIRA-12", suggest: []string{"fix/jira-12", "feat/jira-12", "chore/jira-12"}},
+		{name: "no prefix", branch: "login page!", suggest: []string{"feat/login-page", "fix/login-page", "chore/login-page"}},
+		{name: "custom patterns", opts: Options{"patterns": []any{`^[A-Z]+-[0-9]+-[a-z-]+$`}, "types": []any{}}, branch: "OPS-1-fix-login", allowed: true},
+		{name: "nothing to suggest", opts: Options{"patterns": []any{`^[A-Z]+-[0-9]+-[a-z-]+$`}, "types": []any{}}, branch: "login"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := newBranchNamePolicy(tt.opts)
+			if err != nil {
+				t.Fatalf("newBranchNamePolicy() error = %v", err)
+			}
+			if got := policy.allowed(tt.branch); got != tt.allowed {
+				t.Errorf("allowed(%q) = %v, want %v", tt.branch, got, tt.allowed)
+			}
+			if tt.allowed {
+				return
+			}
+			if got := policy.suggest(tt.branch); !reflect.DeepEqual(got, tt.suggest) {
+				t.Errorf("suggest(%q) = %q, want %q", tt.branch, got, tt.suggest)
+			}
+			for _, suggestion := range tt.suggest {
+				if !policy.allowed(suggestion) {
+					t.Errorf("suggestion %q is not allowed", suggestion)
+				}
+			}
+		})
+	}
+}
+
+func TestNewBranchNamePolicyErrors(t *testing.T) {
+	for _, opts := range []Options{
+		{"patterns": []any{"("}},
+		{"exempt": []any{"[main"}},
+		{"pattern": "x"},
+	} {
+		if _, err := newBranchNamePolicy(opts); err == nil {
+			t.Errorf("newBranchNamePolicy(%v) succeeded", opts)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package checks implements omnihook's built-in hooks: checks written in Go
+// that run inside omnihook instead of as external scripts.
+package checks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Check is a built-in hook. Hook files select one with "builtin: <name>".
+type Check interface {
+	// Name identifies the check in hook definitions.
+	Name() string
+	// Description says what the check looks for.
+	Description() string
+	// HookTypes lists the git hooks the check can run as.
+	HookTypes() []string
+	// Run checks the files in ctx and returns the problems found. An error
+	// means the check itself could not run.
+	Run(ctx *Context) ([]Finding, error)
+}
+
+// OptionsValidator is implemented by checks that can reject bad options when
+// a hook is installed rather than when it first runs.
+type OptionsValidator interface {
+	ValidateOptions(opts Options) error
+}
+
+// Baseliner is implemented by checks that can record the problems a
+// repository already has, so that only new ones fail the hook.
+type Baseliner interface {
+	// WriteBaseline records the problems in ctx.Files and returns the file
+	// it wrote and how many problems it recorded.
+	WriteBaseline(ctx *Context) (string, int, error)
+}
+
+// Context is what a check runs against.
+type Context struct {
+	// HookType is the git hook the check is running as.
+	HookType string
+	// RepoRoot is the top-level directory of the repository.
+	RepoRoot string
+	// Files are the files to check, relative to RepoRoot: the staged files,
+	// or those chosen with run --files, --all-files or --from-ref.
+	Files []string
+	// Staged is set when Files are the files staged for a commit, rather
+	// than files chosen on the command line whose whole content is checked.
+	Staged bool
+	// Args are the hook's arguments, followed by what git passed to the
+	// hook, such as the commit message of a commit-msg hook.
+	Args []string
+	// Options configure the check, from the hook definition and overrides.
+	Options Options
+	// CommitMsg is the message of a commit-msg hook, read from
+	// CommitMsgFile when git passed one.
+	CommitMsg     string
+	CommitMsgFile string
+	// Remote and RemoteURL name where a pre-push hook is pushing to.
+	Remote    string
+	RemoteURL string
+	// Updates are the refs a pre-push hook is pushing.
+	Updates []RefUpdate
+}
+
+// Severity says whether a finding fails the hook.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a problem reported by a check, located as precisely as the
+// check can.
+type Finding struct {
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Rule     string   `json:"rule,omitempty"`
+	Message  string   `json:"message"`
+	Severity Severity `json:"severity,omitempty"`
+}
+
+// IsError reports whether the finding fails the hook. Findings without a
+// severity are errors.
+func (f Finding) IsError() bool {
+	return f.Severity != SeverityWarning
+}
+
+// String formats the finding as "file:line:column: message [rule]", leaving
+// out whatever is unknown.
+func (f Finding) String() string {
+	var b strings.Builder
+	if f.File != "" {
+		b.WriteString(f.File)
+		if f.Line > 0 {
+			fmt.Fprintf(&b, ":%d", f.Line)
+			if f.Column > 0 {
+				fmt.Fprintf(&b, ":%d", f.Column)
+			}
+		}
+		b.WriteString(": ")
+	}
+	if f.Severity == SeverityWarning {
+		b.WriteString("warning: ")
+	}
+	b.WriteString(f.Message)
+	if f.Rule != "" {
+		fmt.Fprintf(&b, " [%s]", f.Rule)
+	}
+	return b.String()
+}
+
+// Options are the settings of a check, as written under options in YAML.
+type Options map[string]any
+
+// Decode fills v, a pointer to a struct with yaml tags, from the options.
+// Options v has no field for are an error, so typos do not go unnoticed.
+// The options are decoded one key at a time so that an error names the
+// option it is about.
+func (o Options) Decode(v any) error {
+	keys := make([]string, 0, len(o))
+	for key := range o {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		data, err := yaml.Marshal(map[string]any{key: o[key]})
+		if err != nil {
+			return fmt.Errorf("invalid option '%s': %w", key, err)
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(v); err != nil {
+			return optionError(key, err)
+		}
+	}
+	return nil
+}
+
+var (
+	unmarshalLine  = regexp.MustCompile(`^line \d+: `)
+	unknownField   = regexp.MustCompile(`^field (\S+) not found in type `)
+	unmarshalError = regexp.MustCompile("^cannot unmarshal !!(\\w+)(?: `(.*)`)? into (.+)$")
+)
+
+// optionError rewrites the error decoding the option key into one that
+// speaks of options and values rather than YAML lines and Go types, which
+// mean nothing to someone editing a hook file.
+func optionError(key string, err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) || len(typeErr.Errors) == 0 {
+		return fmt.Errorf("invalid option '%s': %s", key, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	message := unmarshalLine.ReplaceAllString(typeErr.Errors[0], "")
+	if match := unknownField.FindStringSubmatch(message); match != nil {
+		if match[1] == key {
+			return fmt.Errorf("unknown option '%s'", key)
+		}
+		return fmt.Errorf("invalid option '%s': unknown field '%s'", key, match[1])
+	}
+	if match := unmarshalError.FindStringSubmatch(message); match != nil {
+		got := "`" + match[2] + "`"
+		if match[2] == "" {
+			got = yamlKind(match[1])
+		}
+		return fmt.Errorf("invalid option '%s': expected %s, got %s", key, goKind(match[3]), got)
+	}
+	return fmt.Errorf("invalid option '%s': %s", key, message)
+}
+
+// yamlKind describes a YAML tag, such as map, in words.
+func yamlKind(tag string) string {
+	switch tag {
+	case "map":
+		return "a map"
+	case "seq":
+		return "a list"
+	case "null":
+		return "nothing"
+	}
+	return "a " + tag
+}
+
+// goKind describes the Go type a value was decoded into in words.
+func goKind(typ string) string {
+	typ = strings.TrimPrefix(typ, "*")
+	switch {
+	case strings.HasPrefix(typ, "[]"):
+		return "a list"
+	case strings.HasPrefix(typ, "map["):
+		return "a map"
+	case typ == "string":
+		return "a string"
+	case typ == "bool":
+		return "true or false"
+	case strings.HasPrefix(typ, "int"), strings.HasPrefix(typ, "uint"):
+		return "a whole number"
+	case strings.HasPrefix(typ, "float"):
+		return "a number"
+	}
+	return "a map"
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Check)
+)
+
+// Register makes a check available to hook definitions. It panics if a check
+// of the same name is already registered.
+func Register(check Check) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[check.Name()]; exists {
+		panic("checks: duplicate check " + check.Name())
+	}
+	registry[check.Name()] = check
+}
+
+// Lookup returns the check registered under name.
+func Lookup(name string) (Check, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	check, ok := registry[name]
+	return check, ok
+}
+
+// All returns every registered check, sorted by name.
+func All() []Check {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	all := make([]Check, 0, len(registry))
+	for _, check := range registry {
+		all = append(all, check)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name() < all[j].Name() })
+	return all
+}
+
+// Supports reports whether check can run as hookType.
+func Supports(check Check, hookType string) bool {
+	return slices.Contains(check.HookTypes(), hookType)
+}
@@ -0,0 +1,128 @@
+package checks
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type fakeCheck struct{ name string }
+
+func (c fakeCheck) Name() string                  { return c.name }
+func (fakeCheck) Description() string             { return "fake" }
+func (fakeCheck) HookTypes() []string             { return []string{"pre-commit"} }
+func (fakeCheck) Run(*Context) ([]Finding, error) { return nil, nil }
+
+func TestRegisterLookup(t *testing.T) {
+	Register(fakeCheck{name: "zz-fake"})
+
+	check, ok := Lookup("zz-fake")
+	if !ok || check.Name() != "zz-fake" {
+		t.Fatalf("Lookup(zz-fake) = %v, %v, want the registered check", check, ok)
+	}
+	if !Supports(check, "pre-commit") || Supports(check, "pre-push") {
+		t.Errorf("Supports() does not follow HookTypes()")
+	}
+	if _, ok := Lookup("missing"); ok {
+		t.Errorf("Lookup(missing) found a check")
+	}
+
+	var names []string
+	for _, check := range All() {
+		names = append(names, check.Name())
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("All() = %v, want sorted by name", names)
+	}
+	for _, builtin := range []string{"branch-name", "branch-protection", "commit-message", "filenames", "large-files", "leftovers", "secrets", "syntax", "whitespace", "zz-fake"} {
+		if !slices.Contains(names, builtin) {
+			t.Errorf("All() = %v, missing %s", names, builtin)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("registering a duplicate check did not panic")
+		}
+	}()
+	Register(fakeCheck{name: "zz-fake"})
+}
+
+func TestOptionsDecode(t *testing.T) {
+	type nested struct {
+		Files []string `yaml:"files"`
+	}
+	type options struct {
+		Mode  string   `yaml:"mode"`
+		Limit *int     `yaml:"limit"`
+		Rules []nested `yaml:"rules"`
+	}
+	limit := 3
+	tests := []struct {
+		name    string
+		opts    Options
+		want    options
+		wantErr string
+	}{
+		{name: "no options keep defaults", want: options{Mode: "check"}},
+		{name: "known fields", opts: Options{"mode": "fix", "limit": 3, "rules": []any{map[string]any{"files": []any{"*.md"}}}}, want: options{Mode: "fix", Limit: &limit, Rules: []nested{{Files: []string{"*.md"}}}}},
+		{name: "unknown field", opts: Options{"mdoe": "fix"}, wantErr: "unknown option 'mdoe'"},
+		{name: "unknown nested field", opts: Options{"rules": []any{map[string]any{"file": "*.md"}}}, wantErr: "invalid option 'rules': unknown field 'file'"},
+		{name: "wrong type", opts: Options{"limit": "three"}, wantErr: "invalid option 'limit': expected a whole number, got `three`"},
+		{name: "list for a string", opts: Options{"mode": []any{"fix"}}, wantErr: "invalid option 'mode': expected a string, got a list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := options{Mode: "check"}
+			err := tt.opts.Decode(&got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOptionsRejectsUnknownKeys(t *testing.T) {
+	for _, check := range All() {
+		validator, ok := check.(OptionsValidator)
+		if !ok {
+			continue
+		}
+		t.Run(check.Name(), func(t *testing.T) {
+			if err := validator.ValidateOptions(nil); err != nil {
+				t.Errorf("ValidateOptions(nil) error = %v", err)
+			}
+			if err := validator.ValidateOptions(Options{"noSuchOption": true}); err == nil {
+				t.Errorf("ValidateOptions() accepted an unknown option")
+			}
+		})
+	}
+}
+
+func TestFindingString(t *testing.T) {
+	tests := []struct {
+		finding Finding
+		want    string
+	}{
+		{Finding{Message: "bad"}, "bad"},
+		{Finding{File: "a.go", Message: "bad", Rule: "r"}, "a.go: bad [r]"},
+		{Finding{File: "a.go", Line: 3, Column: 7, Message: "bad"}, "a.go:3:7: bad"},
+		{Finding{File: "a.go", Column: 7, Message: "bad"}, "a.go: bad"},
+		{Finding{File: "a.go", Line: 3, Message: "bad", Severity: SeverityWarning}, "a.go:3: warning: bad"},
+	}
+	for _, tt := range tests {
+		if got := tt.finding.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package checks
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+func init() {
+	Register(commitMessageCheck{})
+}
+
+// defaultCommitTypes are the Conventional Commits types allowed unless the
+// types option says otherwise.
+var defaultCommitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+var (
+	conventionalHeader = regexp.MustCompile(`^([^():!\s]+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+	trailerLine        = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*|BREAKING CHANGE): (.+)$`)
+	signedOffValue     = regexp.MustCompile(`^.+ <[^<>@\s]+@[^<>\s]+>$`)
+)
+
+// scissorsLine marks where git commit --verbose starts the diff, which is
+// not part of the message. It follows the comment character.
+const scissorsLine = " ------------------------ >8 ------------------------"
+
+// autoCommentChars are the comment characters git picks from, in order, when
+// core.commentChar is auto.
+const autoCommentChars = "#;@!$%^&|:"
+
+// commitMessageOptions are the options of the commit-message check.
+type commitMessageOptions struct {
+	// Conventional requires a Conventional Commits header; the other rules
+	// apply either way.
+	Conventional *bool `yaml:"conventional"`
+	// Types and Scopes are those allowed in the header. Any scope is
+	// allowed when Scopes is empty.
+	Types        []string `yaml:"types"`
+	Scopes       []string `yaml:"scopes"`
+	RequireScope bool     `yaml:"requireScope"`
+	// MaxSubjectLength and MaxBodyLineLength limit the length of the first
+	// line and of the body's lines; 0 leaves them unlimited.
+	MaxSubjectLength  *int `yaml:"maxSubjectLength"`
+	MaxBodyLineLength *int `yaml:"maxBodyLineLength"`
+	// RequireTrailers lists trailers every message must end with, such as
+	// Signed-off-by.
+	RequireTrailers []string `yaml:"requireTrailers"`
+	// TicketPattern is a regular expression a ticket ID in the message must
+	// match, such as '[A-Z]+-[0-9]+'.
+	TicketPattern string `yaml:"ticketPattern"`
+	// ForbiddenWords are words the message must not contain, matched as
+	// whole words regardless of case.
+	ForbiddenWords []string `yaml:"forbiddenWords"`
+}
+
+// commitMessagePolicy is the commit-message check configured by its options.
+type commitMessagePolicy struct {
+	conventional      bool
+	types             []string
+	scopes            []string
+	requireScope      bool
+	maxSubjectLength  int
+	maxBodyLineLength int
+	requireTrailers   []string
+	ticket            *regexp.Regexp
+	forbidden         []*regexp.Regexp
+}
+
+// messageCleanup is how git cleans up the message once the hook has run.
+type messageCleanup struct {
+	// comment starts comment lines and the scissors line.
+	comment string
+	// stripComments drops comment lines and scissors drops the scissors
+	// line and everything after it.
+	stripComments bool
+	scissors      bool
+	// verbatim keeps the message exactly as written.
+	verbatim bool
+}
+
+// messageLine is a line of the commit message with its line number in the
+// message file.
+type messageLine struct {
+	number int
+	text   string
+}
+
+type commitMessageCheck struct{}
+
+func (commitMessageCheck) Name() string { return "commit-message" }
+
+func (commitMessageCheck) Description() string {
+	return "Enforces Conventional Commits headers, line lengths, trailers, ticket IDs and forbidden words"
+}
+
+func (commitMessageCheck) HookTypes() []string { return []string{"commit-msg"} }
+
+func (commitMessageCheck) ValidateOptions(opts Options) error {
+	_, err := newCommitMessagePolicy(opts)
+	return err
+}
+
+func (commitMessageCheck) Run(ctx *Context) ([]Finding, error) {
+	policy, err := newCommitMessagePolicy(ctx.Options)
+	if err != nil {
+		return nil, err
+	}
+	file := ctx.CommitMsgFile
+	if file == "" {
+		file = "commit message"
+	}
+	findings := policy.check(messageLines(ctx.CommitMsg, commitCleanup(ctx, ctx.CommitMsg)))
+	for i := range findings {
+		findings[i].File = file
+	}
+	return findings, nil
+}
+
+func newCommitMessagePolicy(opts Options) (*commitMessagePolicy, error) {
+	var options commitMessageOptions
+	if err := opts.Decode(&options); err != nil {
+		return nil, err
+	}
+	policy := &commitMessagePolicy{
+		conventional:      true,
+		types:             defaultCommitTypes,
+		scopes:            options.Scopes,
+		requireScope:      options.RequireScope,
+		maxSubjectLength:  72,
+		maxBodyLineLength: 100,
+		requireTrailers:   options.RequireTrailers,
+	}
+	if options.Conventional != nil {
+		policy.conventional = *options.Conventional
+	}
+	if len(options.Types) > 0 {
+		policy.types = options.Types
+	}
+	if options.MaxSubjectLength != nil {
+		policy.maxSubjectLength = *options.MaxSubjectLength
+	}
+	if options.MaxBodyLineLength != nil {
+		policy.maxBodyLineLength = *options.MaxBodyLineLength
+	}
+	if options.TicketPattern != "" {
+		re, err := regexp.Compile(options.TicketPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ticketPattern: %w", err)
+		}
+		policy.ticket = re
+	}
+	for _, word := range options.ForbiddenWords {
+		policy.forbidden = append(policy.forbidden, regexp.MustCompile(`(?i)(?:^|\W)(`+regexp.QuoteMeta(word)+`)(?:\W|$)`))
+	}
+	return policy, nil
+}
+
+// commitCleanup returns how git will clean up message, following
+// commit.cleanup and core.commentChar. A --cleanup given on the command line
+// is not passed on to hooks, so only the configured mode is known.
+func commitCleanup(ctx *Context, message string) messageCleanup {
+	comment := gitConfig(ctx, "core.commentChar", "#")
+	if comment == "auto" {
+		comment = autoCommentChar(message)
+	}
+	mode := gitConfig(ctx, "commit.cleanup", "default")
+	if mode == "default" {
+		// Git keeps comments in messages that were not edited, such as
+		// those given with -m, and tells hooks so through GIT_EDITOR
+		mode = "strip"
+		if os.Getenv("GIT_EDITOR") == ":" {
+			mode = "whitespace"
+		}
+	}
+	switch mode {
+	case "verbatim":
+		return messageCleanup{verbatim: true}
+	case "whitespace":
+		return messageCleanup{comment: comment}
+	case "scissors":
+		return messageCleanup{comment: comment, scissors: true}
+	}
+	return messageCleanup{comment: comment, stripComments: true, scissors: true}
+}
+
+// autoCommentChar guesses the comment character git picked for message when
+// core.commentChar is auto. Git picks one no line of the message starts
+// with, and starts the scissors line and the help it appends with it.
+func autoCommentChar(message string) string {
+	lines := strings.Split(strings.TrimRight(message, "\r\n"), "\n")
+	for _, c := range autoCommentChars {
+		if slices.Contains(lines, string(c)+scissorsLine) {
+			return string(c)
+		}
+	}
+	if last := lines[len(lines)-1]; last != "" && strings.ContainsRune(autoCommentChars, rune(last[0])) {
+		return last[:1]
+	}
+	return "#"
+}
+
+// messageLines returns the lines git keeps of a message: comments and what
+// follows the scissors line are dropped as cleanup says, as are blank lines
+// at either end.
+func messageLines(message string, cleanup messageCleanup) []messageLine {
+	var lines []messageLine
+	for i, text := range strings.Split(message, "\n") {
+		if cleanup.verbatim {
+			lines = append(lines, messageLine{number: i + 1, text: text})
+			continue
+		}
+		text = strings.TrimRight(text, "\r")
+		if cleanup.scissors && text == cleanup.comment+scissorsLine {
+			break
+		}
+		if cleanup.stripComments && strings.HasPrefix(text, cleanup.comment) {
+			continue
+		}
+		lines = append(lines, messageLine{number: i + 1, text: strings.TrimRight(text, " \t")})
+	}
+	if cleanup.verbatim {
+		// Only the newline ending the last line goes
+		if len(lines) > 0 && lines[len(lines)-1].text == "" {
+			lines = lines[:len(lines)-1]
+		}
+		return lines
+	}
+	// Leading blank lines are dropped by git too
+	for len(lines) > 0 && lines[0].text == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1].text == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func (p *commitMessagePolicy) check(lines []messageLine) []Finding {
+	if len(lines) == 0 {
+		return []Finding{{Line: 1, Rule: "empty", Message: "commit message is empty"}}
+	}
+	subject := lines[0]
+	// Messages git writes itself and commits about to be squashed away are
+	// left alone
+	for _, prefix := range []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(subject.text, prefix) {
+			return nil
+		}
+	}
+
+	var findings []Finding
+	report := func(line messageLine, column int, rule, format string, args ...any) {
+		findings = append(findings, Finding{Line: line.number, Column: column, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if p.conventional {
+		p.checkHeader(subject, report)
+	}
+	if n := len([]rune(subject.text)); p.maxSubjectLength > 0 && n > p.maxSubjectLength {
+		report(subject, p.maxSubjectLength+1, "subject-length", "subject is %d characters long, the limit is %d", n, p.maxSubjectLength)
+	}
+	if len(lines) > 1 && lines[1].text != "" {
+		report(lines[1], 1, "blank-line", "the subject must be followed by a blank line")
+	}
+
+	body, trailers := splitTrailers(lines[1:])
+	for _, line := range body {
+		// Long URLs and other unbreakable words cannot be wrapped
+		n := len([]rune(line.text))
+		if p.maxBodyLineLength > 0 && n > p.maxBodyLineLength && strings.ContainsAny(line.text, " \t") {
+			report(line, p.maxBodyLineLength+1, "body-line-length", "line is %d characters long, wrap the body at %d", n, p.maxBodyLineLength)
+		}
+	}
+
+	for _, required := range p.requireTrailers {
+		found := false
+		for _, line := range trailers {
+			key, value, _ := strings.Cut(line.text, ": ")
+			if !strings.EqualFold(key, required) {
+				continue
+			}
+			found = true
+			if strings.EqualFold(required, "Signed-off-by") && !signedOffValue.MatchString(value) {
+				report(line, len(key)+3, "trailer", "Signed-off-by must be \"Name <email>\"")
+			}
+		}
+		if !found {
+			last := lines[len(lines)-1]
+			report(last, 0, "trailer", "missing %s trailer, add it to the last paragraph", required)
+		}
+	}
+
+	if p.ticket != nil {
+		found := false
+		for _, line := range lines {
+			if p.ticket.MatchString(line.text) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report(subject, 0, "ticket", "no ticket ID matching %s in the message", p.ticket)
+		}
+	}
+
+	for _, line := range lines {
+		for _, word := range p.forbidden {
+			if loc := word.FindStringSubmatchIndex(line.text); loc != nil {
+				report(line, loc[2]+1, "forbidden-word", "%q is not allowed in commit messages", line.text[loc[2]:loc[3]])
+			}
+		}
+	}
+	return findings
+}
+
+// checkHeader checks the subject is a Conventional Commits header:
+// "type(scope)!: description".
+func (p *commitMessagePolicy) checkHeader(subject messageLine, report func(messageLine, int, string, string, ...any)) {
+	match := conventionalHeader.FindStringSubmatchIndex(subject.text)
+	if match == nil {
+		report(subject, 1, "header-format", "subject must look like \"type(scope): description\", with type one of: %s", strings.Join(p.types, ", "))
+		return
+	}
+	group := func(i int) string {
+		if match[2*i] < 0 {
+			return ""
+		}
+		return subject.text[match[2*i]:match[2*i+1]]
+	}
+
+	if commitType := group(1); !slices.Contains(p.types, commitType) {
+		report(subject, 1, "type", "type %q is not allowed, use one of: %s", commitType, strings.Join(p.types, ", "))
+	}
+	scope := group(2)
+	switch {
+	case match[4] < 0 && p.requireScope:
+		report(subject, match[3]+1, "scope", "a scope is required, as in \"%s(scope): ...\"", group(1))
+	case match[4] >= 0 && scope == "":
+		report(subject, match[4]+1, "scope", "scope is empty")
+	case scope != "" && len(p.scopes) > 0 && !slices.Contains(p.scopes, scope):
+		report(subject, match[4]+1, "scope", "scope %q is not allowed, use one of: %s", scope, strings.Join(p.scopes, ", "))
+	}
+	if description := group(4); strings.TrimSpace(description) == "" {
+		report(subject, match[8]+1, "subject-empty", "description after the type is empty")
+	}
+}
+
+// splitTrailers separates the trailers, the last paragraph when every line
+// of it is "Key: value", from the rest of the body.
+func splitTrailers(lines []messageLine) ([]messageLine, []messageLine) {
+	start := len(lines)
+	for start > 0 && lines[start-1].text != "" {
+		start--
+	}
+	// Trailers are separated from the subject by a blank line
+	if start == 0 {
+		return lines, nil
+	}
+	for _, line := range lines[start:] {
+		if !trailerLine.MatchString(line.text) {
+			return lines, nil
+		}
+	}
+	return lines[:start], lines[start:]
+}
@@ -0,0 +1,118 @@
+package checks
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommitMessagePolicyCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		message string
+		// want lists the rules reported, as "rule@line:column".
+		want []string
+	}{
+		{name: "valid header", message: "feat(api): add endpoint\n\nLonger explanation.\n"},
+		{name: "breaking change", message: "feat!: drop v1 API"},
+		{name: "not conventional", message: "Add endpoint", want: []string{"header-format@1:1"}},
+		{name: "unknown type", message: "feature: add endpoint", want: []string{"type@1:1"}},
+		{name: "empty scope", message: "fix(): typo", want: []string{"scope@1:5"}},
+		{name: "required scope", opts: Options{"requireScope": true}, message: "fix: typo", want: []string{"scope@1:4"}},
+		{name: "scope not allowed", opts: Options{"scopes": []any{"api", "cli"}}, message: "fix(web): typo", want: []string{"scope@1:5"}},
+		{name: "empty description", message: "fix:  ", want: []string{"header-format@1:1"}},
+		{name: "custom types", opts: Options{"types": []any{"change"}}, message: "feat: x", want: []string{"type@1:1"}},
+		{name: "not conventional allowed", opts: Options{"conventional": false}, message: "Add endpoint"},
+		{name: "subject too long", message: "fix: " + strings.Repeat("x", 70), want: []string{"subject-length@1:73"}},
+		{name: "no blank line", message: "fix: typo\nbody", want: []string{"blank-line@2:1"}},
+		{name: "long body line", message: "fix: typo\n\n" + strings.Repeat("word ", 30), want: []string{"body-line-length@3:101"}},
+		{name: "long URL", message: "fix: typo\n\nhttps://example.com/" + strings.Repeat("x", 120)},
+		{name: "comments and scissors", message: "fix: typo\n# " + strings.Repeat("x", 200) + "\n#" + scissorsLine + "\ndiff --git a/x b/x\n"},
+		{name: "missing trailer", opts: Options{"requireTrailers": []any{"Signed-off-by"}}, message: "fix: typo\n\nBody.", want: []string{"trailer@3:0"}},
+		{name: "trailer", opts: Options{"requireTrailers": []any{"Signed-off-by"}}, message: "fix: typo\n\nSigned-off-by: Jo Doe <jo@example.com>"},
+		{name: "bad sign-off", opts: Options{"requireTrailers": []any{"Signed-off-by"}}, message: "fix: typo\n\nSigned-off-by: Jo", want: []string{"trailer@3:16"}},
+		{name: "trailer in body", opts: Options{"requireTrailers": []any{"Signed-off-by"}}, message: "fix: typo\n\nSigned-off-by: Jo Doe <jo@example.com>\nmore text", want: []string{"trailer@4:0"}},
+		{name: "ticket", opts: Options{"ticketPattern": "[A-Z]+-[0-9]+"}, message: "fix: typo\n\nRefs: OPS-12"},
+		{name: "missing ticket", opts: Options{"ticketPattern": "[A-Z]+-[0-9]+"}, message: "fix: typo", want: []string{"ticket@1:0"}},
+		{name: "forbidden word", opts: Options{"forbiddenWords": []any{"wip"}}, message: "fix: typo\n\nstill WIP here", want: []string{"forbidden-word@3:7"}},
+		{name: "forbidden word inside another", opts: Options{"forbiddenWords": []any{"wip"}}, message: "fix: wipe cache"},
+		{name: "merge", message: "Merge branch 'main' into feature"},
+		{name: "fixup", message: "fixup! fix: typo"},
+		{name: "empty", message: "# only comments\n\n", want: []string{"empty@1:0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := newCommitMessagePolicy(tt.opts)
+			if err != nil {
+				t.Fatalf("newCommitMessagePolicy() error = %v", err)
+			}
+			var got []string
+			for _, finding := range policy.check(messageLines(tt.message, messageCleanup{comment: "#", stripComments: true, scissors: true})) {
+				got = append(got, fmt.Sprintf("%s@%d:%d", finding.Rule, finding.Line, finding.Column))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("check(%q) = %v, want %v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitMessageCleanup(t *testing.T) {
+	long := strings.Repeat("word ", 30)
+	tests := []struct {
+		name   string
+		config []string
+		// editor is GIT_EDITOR as git sets it for the hook.
+		editor  string
+		message string
+		want    []string
+	}{
+		{name: "comment dropped", message: "fix: typo\n\n# " + long + "\n"},
+		{name: "comment char", config: []string{"core.commentChar", ";"}, message: "fix: typo\n\n# " + long + "\n; " + long + "\n", want: []string{"body-line-length@3:101"}},
+		{name: "auto comment char", config: []string{"core.commentChar", "auto"}, message: "fix: typo\n\n# " + long + "\n; Please enter the commit message\n", want: []string{"body-line-length@3:101"}},
+		{name: "not edited", editor: ":", message: "fix: typo\n\n# " + long + "\n", want: []string{"body-line-length@3:101"}},
+		{name: "verbatim", config: []string{"commit.cleanup", "verbatim"}, message: "fix: typo\n\n# " + long + "\n", want: []string{"body-line-length@3:101"}},
+		{name: "whitespace", config: []string{"commit.cleanup", "whitespace"}, message: "fix: typo\n\n# " + long + "\n", want: []string{"body-line-length@3:101"}},
+		{name: "scissors keeps comments", config: []string{"commit.cleanup", "scissors"}, message: "fix: typo\n\n# " + long + "\n#" + scissorsLine + "\n" + long + "\n", want: []string{"body-line-length@3:101"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+			if tt.config != nil {
+				runGit(t, dir, append([]string{"config"}, tt.config...)...)
+			}
+			editor := tt.editor
+			if editor == "" {
+				editor = "vi"
+			}
+			t.Setenv("GIT_EDITOR", editor)
+
+			ctx := &Context{HookType: "commit-msg", RepoRoot: dir, CommitMsg: tt.message}
+			findings, err := commitMessageCheck{}.Run(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, finding := range findings {
+				got = append(got, fmt.Sprintf("%s@%d:%d", finding.Rule, finding.Line, finding.Column))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCommitMessagePolicyErrors(t *testing.T) {
+	for _, opts := range []Options{
+		{"ticketPattern": "("},
+		{"maxSubjectLength": "long"},
+		{"typse": []any{"feat"}},
+	} {
+		if _, err := newCommitMessagePolicy(opts); err == nil {
+			t.Errorf("newCommitMessagePolicy(%v) succeeded", opts)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package checks
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+func init() {
+	Register(filenamesCheck{})
+}
+
+// filenameRules are the rules of the filenames check, which the disable
+// option can turn off.
+var filenameRules = []string{"case-collision", "illegal-character", "reserved-name", "path-length", "symlink", "executable-bit"}
+
+// Characters Windows does not allow in file names, besides control
+// characters.
+const windowsIllegalCharacters = `<>:"\|?*`
+
+// windowsReservedNames are device names Windows does not allow as file
+// names, whatever their extension.
+var windowsReservedNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// filenamesOptions are the options of the filenames check.
+type filenamesOptions struct {
+	// Disable lists rules that are not checked.
+	Disable []string `yaml:"disable"`
+	// MaxPathLength is the longest path allowed, in characters; 0 leaves it
+	// unlimited.
+	MaxPathLength *int `yaml:"maxPathLength"`
+	// Exclude lists globs of files that are not checked.
+	Exclude []string `yaml:"exclude"`
+}
+
+// filenamesPolicy is the filenames check configured by its options.
+type filenamesPolicy struct {
+	disabled      []string
+	maxPathLength int
+	exclude       []*Glob
+}
+
+type filenamesCheck struct{}
+
+func (filenamesCheck) Name() string { return "filenames" }
+
+func (filenamesCheck) Description() string {
+	return "Blocks case collisions, unportable or long paths, symlinks leaving the repository and wrong executable bits"
+}
+
+func (filenamesCheck) HookTypes() []string { return []string{"pre-commit"} }
+
+func (filenamesCheck) ValidateOptions(opts Options) error {
+	_, err := newFilenamesPolicy(opts)
+	return err
+}
+
+func (filenamesCheck) Run(ctx *Context) ([]Finding, error) {
+	policy, err := newFilenamesPolicy(ctx.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Everything comes from the index, so only names and modes are read
+	// besides the symlink targets and first bytes of the files checked
+	entries, err := indexEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(ctx.Files))
+	for _, file := range ctx.Files {
+		wanted[file] = !matchAny(policy.exclude, file)
+	}
+	var checked []blob
+	var shas []string
+	for _, entry := range entries {
+		if wanted[entry.Path] {
+			checked = append(checked, entry)
+			if entry.Mode == "120000" || entry.Mode == "100755" || entry.Mode == "100644" {
+				shas = append(shas, entry.SHA)
+			}
+		}
+	}
+	heads, err := blobHeads(ctx, shas)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	report := func(file, rule, format string, args ...any) {
+		if !slices.Contains(policy.disabled, rule) {
+			findings = append(findings, Finding{File: file, Rule: rule, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+	collisions := caseCollisions(entries)
+	reported := make(map[string]bool)
+	for _, entry := range checked {
+		// Directories collide as well as files
+		for _, name := range pathPrefixes(entry.Path) {
+			folded := strings.ToLower(name)
+			if names := collisions[folded]; len(names) > 1 && !reported[folded] {
+				reported[folded] = true
+				report(entry.Path, "case-collision", "%s differ only in case and collide on case-insensitive file systems such as those of macOS and Windows", strings.Join(names, " and "))
+			}
+		}
+		policy.checkName(entry.Path, report)
+
+		head := heads[entry.SHA]
+		switch entry.Mode {
+		case "120000":
+			target := string(head)
+			if symlinkEscapes(entry.Path, target) {
+				report(entry.Path, "symlink", "symbolic link to %q points outside the repository", target)
+			}
+		case "100755":
+			if !bytes.HasPrefix(head, []byte("#!")) && !isBinary(head) {
+				report(entry.Path, "executable-bit", "executable but has no shebang; add one or run 'git add --chmod=-x %s'", entry.Path)
+			}
+		case "100644":
+			if bytes.HasPrefix(head, []byte("#!")) {
+				report(entry.Path, "executable-bit", "has a shebang but is not executable; run 'git add --chmod=+x %s'", entry.Path)
+			}
+		}
+	}
+	return findings, nil
+}
+
+func newFilenamesPolicy(opts Options) (*filenamesPolicy, error) {
+	var options filenamesOptions
+	if err := opts.Decode(&options); err != nil {
+		return nil, err
+	}
+	for _, rule := range options.Disable {
+		if !slices.Contains(filenameRules, rule) {
+			return nil, fmt.Errorf("unknown rule '%s' in disable, expected one of: %s", rule, strings.Join(filenameRules, ", "))
+		}
+	}
+	// Windows limits paths to 260 characters, including the directory the
+	// repository is cloned into
+	policy := &filenamesPolicy{disabled: options.Disable, maxPathLength: 200}
+	if options.MaxPathLength != nil {
+		policy.maxPathLength = *options.MaxPathLength
+	}
+	exclude, err := compileGlobs("exclude", options.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	policy.exclude = exclude
+	return policy, nil
+}
+
+// checkName reports names that cannot be checked out everywhere.
+func (p *filenamesPolicy) checkName(file string, report func(file, rule, format string, args ...any)) {
+	if n := utf8.RuneCountInString(file); p.maxPathLength > 0 && n > p.maxPathLength {
+		report(file, "path-length", "path is %d characters long, the limit is %d", n, p.maxPathLength)
+	}
+	for _, name := range strings.Split(file, "/") {
+		if len(name) > 255 {
+			report(file, "path-length", "name %.40q... is %d bytes long, more than file systems allow", name, len(name))
+		}
+		if i := strings.IndexFunc(name, func(r rune) bool { return r < 0x20 || strings.ContainsRune(windowsIllegalCharacters, r) }); i >= 0 {
+			r, _ := utf8.DecodeRuneInString(name[i:])
+			report(file, "illegal-character", "name %q contains %q, which Windows does not allow", name, r)
+		} else if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+			report(file, "illegal-character", "name %q ends with a dot or space, which Windows drops", name)
+		}
+		base, _, _ := strings.Cut(name, ".")
+		if slices.Contains(windowsReservedNames, strings.ToUpper(strings.TrimRight(base, " "))) {
+			report(file, "reserved-name", "name %q is reserved for a device on Windows", name)
+		}
+	}
+}
+
+// caseCollisions groups the paths of the index entries and their directories
+// by their lower case form.
+func caseCollisions(entries []blob) map[string][]string {
+	names := make(map[string][]string)
+	for _, entry := range entries {
+		for _, name := range pathPrefixes(entry.Path) {
+			folded := strings.ToLower(name)
+			if !slices.Contains(names[folded], name) {
+				names[folded] = append(names[folded], name)
+			}
+		}
+	}
+	return names
+}
+
+// pathPrefixes returns the directories leading to file, then file itself:
+// a, a/b and a/b/c for a/b/c.
+func pathPrefixes(file string) []string {
+	var prefixes []string
+	for i, c := range file {
+		if c == '/' {
+			prefixes = append(prefixes, file[:i])
+		}
+	}
+	return append(prefixes, file)
+}
+
+// symlinkEscapes reports whether a symbolic link at file pointing at target
+// leads outside the repository.
+func symlinkEscapes(file, target string) bool {
+	// Absolute paths, including Windows ones such as C:\ or \\server
+	if strings.HasPrefix(target, "/") || strings.HasPrefix(target, `\`) || len(target) > 1 && target[1] == ':' {
+		return true
+	}
+	resolved := path.Join(path.Dir(file), strings.ReplaceAll(target, `\`, "/"))
+	return resolved == ".." || strings.HasPrefix(resolved, "../")
+}
@@ -0,0 +1,118 @@
+package checks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSymlinkEscapes(t *testing.T) {
+	tests := []struct {
+		file   string
+		target string
+		want   bool
+	}{
+		{"link", "target", false},
+		{"a/link", "../b/target", false},
+		{"a/b/link", "../../target", false},
+		{"a/link", "./b/../c", false},
+		{"link", "..", true},
+		{"link", "../outside", true},
+		{"a/link", "../../outside", true},
+		{"a/link", "b/../../../outside", true},
+		{"link", "/etc/passwd", true},
+		{"link", `C:\Windows`, true},
+		{"link", `\\server\share`, true},
+		{"a/link", `..\..\outside`, true},
+		{"a/link", `..\b`, false},
+	}
+	for _, tt := range tests {
+		if got := symlinkEscapes(tt.file, tt.target); got != tt.want {
+			t.Errorf("symlinkEscapes(%q, %q) = %v, want %v", tt.file, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestFilenamesPolicyCheckName(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		file string
+		// want lists the rules reported, in order.
+		want []string
+	}{
+		{name: "valid", file: "src/main.go"},
+		{name: "illegal character", file: "docs/what?.md", want: []string{"illegal-character"}},
+		{name: "control character", file: "a\tb.txt", want: []string{"illegal-character"}},
+		{name: "trailing dot", file: "dir./file", want: []string{"illegal-character"}},
+		{name: "trailing space", file: "file ", want: []string{"illegal-character"}},
+		{name: "reserved name", file: "src/con.go", want: []string{"reserved-name"}},
+		{name: "reserved name with spaces", file: "lpt1 .txt", want: []string{"reserved-name"}},
+		{name: "reserved directory", file: "aux/file ", want: []string{"reserved-name", "illegal-character"}},
+		{name: "reserved name as a prefix", file: "console.go"},
+		{name: "path too long", file: strings.Repeat("a/", 100) + "b", want: []string{"path-length"}},
+		{name: "name too long", opts: Options{"maxPathLength": 0}, file: strings.Repeat("a", 256), want: []string{"path-length"}},
+		{name: "custom path length", opts: Options{"maxPathLength": 10}, file: "src/main_test.go", want: []string{"path-length"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := newFilenamesPolicy(tt.opts)
+			if err != nil {
+				t.Fatalf("newFilenamesPolicy() error = %v", err)
+			}
+			var got []string
+			policy.checkName(tt.file, func(_, rule, _ string, _ ...any) { got = append(got, rule) })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkName(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaseCollisions(t *testing.T) {
+	entries := []blob{{Path: "README.md"}, {Path: "readme.md"}, {Path: "Docs/a.md"}, {Path: "docs/b.md"}, {Path: "src/main.go"}}
+	got := make(map[string][]string)
+	for folded, names := range caseCollisions(entries) {
+		if len(names) > 1 {
+			got[folded] = names
+		}
+	}
+	want := map[string][]string{
+		"readme.md": {"README.md", "readme.md"},
+		"docs":      {"Docs", "docs"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("caseCollisions() = %v, want %v", got, want)
+	}
+}
+
+func TestPathPrefixes(t *testing.T) {
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"a", []string{"a"}},
+		{"a/b/c", []string{"a", "a/b", "a/b/c"}},
+	}
+	for _, tt := range tests {
+		if got := pathPrefixes(tt.file); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pathPrefixes(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestNewFilenamesPolicyErrors(t *testing.T) {
+	tests := []struct {
+		opts    Options
+		wantErr string
+	}{
+		{Options{"disable": []any{"case"}}, "unknown rule 'case' in disable"},
+		{Options{"exclude": []any{"[x"}}, "exclude"},
+		{Options{"maxLength": 10}, "unknown option 'maxLength'"},
+	}
+	for _, tt := range tests {
+		if _, err := newFilenamesPolicy(tt.opts); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("newFilenamesPolicy(%v) error = %v, want one containing %q", tt.opts, err, tt.wantErr)
+		}
+	}
+}
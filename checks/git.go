@@ -0,0 +1,499 @@
+package checks
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RefUpdate is one ref being pushed, as git describes it to pre-push hooks.
+type RefUpdate struct {
+	LocalRef  string `json:"localRef"`
+	LocalSHA  string `json:"localSha"`
+	RemoteRef string `json:"remoteRef"`
+	RemoteSHA string `json:"remoteSha"`
+}
+
+// IsDelete reports whether the push deletes the remote ref. git reports the
+// missing side of an update as a SHA of all zeros.
+func (u RefUpdate) IsDelete() bool {
+	return strings.Trim(u.LocalSHA, "0") == ""
+}
+
+// IsNew reports whether the push creates the remote ref.
+func (u RefUpdate) IsNew() bool {
+	return strings.Trim(u.RemoteSHA, "0") == ""
+}
+
+// ParseRefUpdates reads the "<local ref> <local sha> <remote ref> <remote
+// sha>" lines git writes to a pre-push hook's standard input.
+func ParseRefUpdates(r io.Reader) ([]RefUpdate, error) {
+	var updates []RefUpdate
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected pre-push input %q", scanner.Text())
+		}
+		updates = append(updates, RefUpdate{LocalRef: fields[0], LocalSHA: fields[1], RemoteRef: fields[2], RemoteSHA: fields[3]})
+	}
+	return updates, scanner.Err()
+}
+
+// pushedRevisions returns the rev-list arguments selecting the commits a
+// push sends: those since the remote ref, or for a new ref those the remote
+// does not have yet.
+func pushedRevisions(ctx *Context, u RefUpdate) []string {
+	if !u.IsNew() {
+		return []string{u.RemoteSHA + ".." + u.LocalSHA}
+	}
+	remotes := "--remotes"
+	if ctx.Remote != "" {
+		remotes += "=" + ctx.Remote
+	}
+	return []string{u.LocalSHA, "--not", remotes}
+}
+
+// git runs a git command in the repository and returns its output.
+func git(ctx *Context, args ...string) ([]byte, error) {
+	return gitInput(ctx, nil, args...)
+}
+
+// gitInput runs a git command reading input from its stdin.
+func gitInput(ctx *Context, input []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = ctx.RepoRoot
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, gitError(args[0], &stderr, err)
+	}
+	return out, nil
+}
+
+// gitError describes a failed git command by what it wrote to stderr.
+func gitError(command string, stderr *bytes.Buffer, err error) error {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("git %s failed: %s", command, msg)
+	}
+	return fmt.Errorf("git %s failed: %w", command, err)
+}
+
+// addedLine is a line added by a diff.
+type addedLine struct {
+	File string
+	Line int
+	Text string
+	// Commit is the commit adding the line, when scanning pushed commits.
+	Commit string
+}
+
+// diffArgs are the options every diff the checks parse is produced with.
+var diffArgs = []string{"-c", "core.quotePath=false"}
+
+// diffPrefixes pin the a/ and b/ path prefixes diffPath expects, which
+// diff.noprefix and diff.mnemonicPrefix would otherwise change.
+var diffPrefixes = []string{"--src-prefix=a/", "--dst-prefix=b/"}
+
+// stagedAddedLines returns the lines the index adds to the files in ctx.
+func stagedAddedLines(ctx *Context) ([]addedLine, error) {
+	args := append(diffArgs, "diff", "--cached", "-U0", "--no-color", "--no-ext-diff", "--diff-filter=ACMR")
+	out, err := git(ctx, append(args, diffPrefixes...)...)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(ctx.Files))
+	for _, file := range ctx.Files {
+		wanted[file] = true
+	}
+	var lines []addedLine
+	for _, line := range parseAddedLines(out) {
+		if wanted[line.File] {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// pushedAddedLines returns the lines added by the commits being pushed.
+func pushedAddedLines(ctx *Context) ([]addedLine, error) {
+	var lines []addedLine
+	for _, update := range ctx.Updates {
+		if update.IsDelete() {
+			continue
+		}
+		args := append(diffArgs, "log", "-p", "-U0", "--no-color", "--no-ext-diff", "--diff-filter=ACMR", "--format=commit %H")
+		args = append(args, diffPrefixes...)
+		out, err := git(ctx, append(args, pushedRevisions(ctx, update)...)...)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, parseAddedLines(out)...)
+	}
+	return lines, nil
+}
+
+// parseAddedLines extracts the added lines from unified diff output, such as
+// that of git diff or git log -p. Lines are counted against the hunk headers,
+// so an added line that looks like a header, such as "++ x" showing as
+// "+++ x", is still taken as content.
+func parseAddedLines(diff []byte) []addedLine {
+	var lines []addedLine
+	var commit, file string
+	// next is the line number of the next new line in the current hunk, of
+	// which oldLeft and newLeft lines are still to come
+	var next, oldLeft, newLeft int
+	scanner := bufio.NewScanner(bytes.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				if file != "" {
+					lines = append(lines, addedLine{File: file, Line: next, Text: text[1:], Commit: commit})
+				}
+				next++
+				newLeft--
+			case strings.HasPrefix(text, "-"):
+				oldLeft--
+			case strings.HasPrefix(text, " "), text == "":
+				next++
+				oldLeft--
+				newLeft--
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, "commit "):
+			commit, file = strings.TrimPrefix(text, "commit "), ""
+		case strings.HasPrefix(text, "diff --git "):
+			file = ""
+		case strings.HasPrefix(text, "+++ "):
+			file = diffPath(strings.TrimPrefix(text, "+++ "))
+		case strings.HasPrefix(text, "@@ "):
+			next, oldLeft, newLeft = hunkRange(text)
+		}
+	}
+	return lines
+}
+
+// diffPath returns the path of a "+++ b/<path>" line, or "" for /dev/null.
+func diffPath(name string) string {
+	if strings.HasPrefix(name, `"`) {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+	}
+	if !strings.HasPrefix(name, "b/") {
+		return ""
+	}
+	return strings.TrimPrefix(name, "b/")
+}
+
+// hunkRange returns the first new line number of a "@@ -a,b +c,d @@" header,
+// and the number of old and new lines the hunk holds. A count left out is 1.
+func hunkRange(header string) (start, oldCount, newCount int) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0
+	}
+	_, oldCount = parseRange(strings.TrimPrefix(fields[1], "-"))
+	start, newCount = parseRange(strings.TrimPrefix(fields[2], "+"))
+	return start, oldCount, newCount
+}
+
+// parseRange parses the "c,d" part of a hunk header.
+func parseRange(text string) (start, count int) {
+	first, length, found := strings.Cut(text, ",")
+	start, _ = strconv.Atoi(first)
+	count = 1
+	if found {
+		count, _ = strconv.Atoi(length)
+	}
+	return start, count
+}
+
+// isBinary guesses whether data is binary the way git does, by looking for a
+// NUL byte near the start.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
+
+// changedLines returns the lines a hook should look at: those added by the
+// staged changes or the pushed commits, or every line of files chosen on the
+// command line. Binary files are left out.
+func changedLines(ctx *Context) ([]addedLine, error) {
+	if ctx.Staged {
+		return stagedAddedLines(ctx)
+	}
+	if len(ctx.Updates) > 0 {
+		return pushedAddedLines(ctx)
+	}
+	var lines []addedLine
+	for _, file := range ctx.Files {
+		data, err := os.ReadFile(filepath.Join(ctx.RepoRoot, filepath.FromSlash(file)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isBinary(data) {
+			continue
+		}
+		for i, text := range strings.Split(string(data), "\n") {
+			lines = append(lines, addedLine{File: file, Line: i + 1, Text: strings.TrimSuffix(text, "\r")})
+		}
+	}
+	return lines, nil
+}
+
+// blob is a file as git stores it.
+type blob struct {
+	Path string
+	SHA  string
+	// Mode is the file mode git records, such as 100644 or 120000 for a
+	// symbolic link.
+	Mode string
+	// Commit is the commit adding the blob, when scanning pushed commits.
+	Commit string
+}
+
+// stagedBlobs returns the index entries of ctx.Files.
+func stagedBlobs(ctx *Context) ([]blob, error) {
+	entries, err := indexEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(ctx.Files))
+	for _, file := range ctx.Files {
+		wanted[file] = true
+	}
+	var blobs []blob
+	for _, entry := range entries {
+		if wanted[entry.Path] {
+			blobs = append(blobs, entry)
+		}
+	}
+	return blobs, nil
+}
+
+// indexEntries returns every file in the index, leaving out submodules.
+func indexEntries(ctx *Context) ([]blob, error) {
+	out, err := git(ctx, "ls-files", "--stage", "-z", "--full-name", ":/")
+	if err != nil {
+		return nil, err
+	}
+	var blobs []blob
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// <mode> <sha> <stage>\t<path>
+		meta, path, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[0] == "160000" {
+			continue
+		}
+		blobs = append(blobs, blob{Path: path, SHA: fields[1], Mode: fields[0]})
+	}
+	return blobs, nil
+}
+
+// pushedBlobs returns the files added or modified by the commits being
+// pushed.
+func pushedBlobs(ctx *Context) ([]blob, error) {
+	var blobs []blob
+	for _, update := range ctx.Updates {
+		if update.IsDelete() {
+			continue
+		}
+		args := append(diffArgs, "log", "--raw", "--no-abbrev", "--no-renames", "--diff-filter=AM", "--format=commit %H")
+		out, err := git(ctx, append(args, pushedRevisions(ctx, update)...)...)
+		if err != nil {
+			return nil, err
+		}
+		var commit string
+		for _, line := range strings.Split(string(out), "\n") {
+			if strings.HasPrefix(line, "commit ") {
+				commit = strings.TrimPrefix(line, "commit ")
+				continue
+			}
+			// :<old mode> <new mode> <old sha> <new sha> <status>\t<path>
+			meta, path, ok := strings.Cut(line, "\t")
+			fields := strings.Fields(meta)
+			if !strings.HasPrefix(line, ":") || !ok || len(fields) != 5 || fields[1] == "160000" {
+				continue
+			}
+			if unquoted, err := strconv.Unquote(path); err == nil && strings.HasPrefix(path, `"`) {
+				path = unquoted
+			}
+			blobs = append(blobs, blob{Path: path, SHA: fields[3], Mode: fields[1], Commit: commit})
+		}
+	}
+	return blobs, nil
+}
+
+// blobSizes returns the size in bytes of each of the blobs.
+func blobSizes(ctx *Context, shas []string) (map[string]int64, error) {
+	sizes := make(map[string]int64, len(shas))
+	if len(shas) == 0 {
+		return sizes, nil
+	}
+	out, err := gitInput(ctx, []byte(strings.Join(shas, "\n")+"\n"), "cat-file", "--batch-check=%(objectname) %(objectsize)")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		sha, size, ok := strings.Cut(line, " ")
+		if n, err := strconv.ParseInt(size, 10, 64); ok && err == nil {
+			sizes[sha] = n
+		}
+	}
+	return sizes, nil
+}
+
+// blobContents returns the content of each of the blobs.
+func blobContents(ctx *Context, shas []string) (map[string][]byte, error) {
+	return readBlobs(ctx, shas, -1)
+}
+
+// headSize is how much of a file is read to tell binary files and Git LFS
+// pointers apart.
+const headSize = 8000
+
+// blobHeads returns up to the first headSize bytes of each of the blobs.
+func blobHeads(ctx *Context, shas []string) (map[string][]byte, error) {
+	return readBlobs(ctx, shas, headSize)
+}
+
+// readBlobs streams the blobs out of git, keeping up to limit bytes of each,
+// or all of them when limit is negative, so that large files are never held
+// in memory only to be cut short.
+func readBlobs(ctx *Context, shas []string, limit int64) (map[string][]byte, error) {
+	contents := make(map[string][]byte, len(shas))
+	if len(shas) == 0 {
+		return contents, nil
+	}
+	cmd := exec.Command("git", "cat-file", "--batch")
+	cmd.Dir = ctx.RepoRoot
+	cmd.Stdin = strings.NewReader(strings.Join(shas, "\n") + "\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, gitError("cat-file", &stderr, err)
+	}
+	out := bufio.NewReader(stdout)
+	// Each blob is "<sha> <type> <size>\n<content>\n", and a missing one
+	// "<sha> missing\n"
+	for {
+		header, err := out.ReadString('\n')
+		if err != nil {
+			break
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			break
+		}
+		keep := size
+		if limit >= 0 {
+			keep = min(size, limit)
+		}
+		content := make([]byte, keep)
+		if _, err := io.ReadFull(out, content); err != nil {
+			break
+		}
+		if _, err := io.CopyN(io.Discard, out, size-keep+1); err != nil {
+			break
+		}
+		contents[fields[0]] = content
+	}
+	// git cannot exit before what it writes is read
+	io.Copy(io.Discard, out)
+	if err := cmd.Wait(); err != nil {
+		return nil, gitError("cat-file", &stderr, err)
+	}
+	return contents, nil
+}
+
+// gitAttribute returns the value of a git attribute for each of the paths,
+// leaving out paths where it is unspecified.
+func gitAttribute(ctx *Context, attribute string, paths []string) (map[string]string, error) {
+	values := make(map[string]string)
+	if len(paths) == 0 {
+		return values, nil
+	}
+	out, err := gitInput(ctx, []byte(strings.Join(paths, "\x00")+"\x00"), "check-attr", "-z", "--stdin", attribute)
+	if err != nil {
+		return nil, err
+	}
+	// <path> NUL <attribute> NUL <value> NUL
+	fields := strings.Split(string(out), "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if value := fields[i+2]; value != "unspecified" {
+			values[fields[i]] = value
+		}
+	}
+	return values, nil
+}
+
+// gitConfig returns the value of a git config key, or fallback when it is
+// not set.
+func gitConfig(ctx *Context, key, fallback string) string {
+	out, err := git(ctx, "config", "--get", key)
+	if err != nil {
+		return fallback
+	}
+	return strings.TrimRight(string(out), "\n")
+}
+
+// isAncestor reports whether commit ancestor is reachable from commit, as it
+// is when a push fast-forwards the remote ref. A commit missing locally is
+// not an ancestor of anything here.
+func isAncestor(ctx *Context, ancestor, commit string) (bool, error) {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, commit)
+	cmd.Dir = ctx.RepoRoot
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return false, nil
+	}
+	if _, err := git(ctx, "cat-file", "-e", ancestor+"^{commit}"); err != nil {
+		return false, nil
+	}
+	return false, fmt.Errorf("git merge-base failed: %w", err)
+}
+
+// currentBranch returns the checked out branch, or "" when HEAD is detached.
+// unborn is set in a repository without commits yet.
+func currentBranch(ctx *Context) (branch string, unborn bool) {
+	out, err := git(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return "", false
+	}
+	_, err = git(ctx, "rev-parse", "--verify", "--quiet", "HEAD")
+	return strings.TrimSpace(string(out)), err != nil
+}
@@ -0,0 +1,154 @@
+package checks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a git repository holding files, staged but not
+// committed, and returns its root.
+func newTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	runGit(t, dir, "config", "user.name", "Test")
+	runGit(t, dir, "config", "commit.gpgsign", "false")
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(files) > 0 {
+		runGit(t, dir, "add", "--all")
+	}
+	return dir
+}
+
+// runGit runs a git command in dir and returns its trimmed output.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestParseAddedLines(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want []addedLine
+	}{
+		{
+			name: "staged diff",
+			diff: `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,0 +4,2 @@ import "fmt"
++func main() {
++}
+@@ -10 +12 @@ func other() {
+-	return 1
++	return 2
+`,
+			want: []addedLine{
+				{File: "main.go", Line: 4, Text: "func main() {"},
+				{File: "main.go", Line: 5, Text: "}"},
+				{File: "main.go", Line: 12, Text: "\treturn 2"},
+			},
+		},
+		{
+			name: "added lines looking like file headers",
+			diff: `diff --git a/notes.md b/notes.md
+--- a/notes.md
++++ b/notes.md
+@@ -1,2 +1,5 @@
+ # Notes
++++ not a header
++-- not a header either
+--- removed, not a header
++token = "secret"
+`,
+			want: []addedLine{
+				{File: "notes.md", Line: 2, Text: "++ not a header"},
+				{File: "notes.md", Line: 3, Text: "-- not a header either"},
+				{File: "notes.md", Line: 4, Text: `token = "secret"`},
+			},
+		},
+		{
+			name: "context lines count",
+			diff: `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,4 @@
+ one
+-two
++deux
++three
+ four
+\ No newline at end of file
+`,
+			want: []addedLine{
+				{File: "a.txt", Line: 2, Text: "deux"},
+				{File: "a.txt", Line: 3, Text: "three"},
+			},
+		},
+		{
+			name: "deleted files are skipped",
+			diff: `diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+diff --git a/new.txt b/new.txt
+new file mode 100644
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++new
+`,
+			want: []addedLine{{File: "new.txt", Line: 1, Text: "new"}},
+		},
+		{
+			name: "commits and quoted paths",
+			diff: `commit 0123456789abcdef0123456789abcdef01234567
+diff --git "a/caf\303\251.txt" "b/caf\303\251.txt"
+--- "a/caf\303\251.txt"
++++ "b/caf\303\251.txt"
+@@ -0,0 +1 @@
++first
+commit fedcba9876543210fedcba9876543210fedcba98
+diff --git a/b.txt b/b.txt
+--- a/b.txt
++++ b/b.txt
+@@ -0,0 +1 @@
++second
+`,
+			want: []addedLine{
+				{File: "café.txt", Line: 1, Text: "first", Commit: "0123456789abcdef0123456789abcdef01234567"},
+				{File: "b.txt", Line: 1, Text: "second", Commit: "fedcba9876543210fedcba9876543210fedcba98"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAddedLines([]byte(tt.diff)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAddedLines() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
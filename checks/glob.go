@@ -0,0 +1,107 @@
+package checks
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Glob matches slash separated paths. "*" and "?" do not cross directories,
+// "**" does, and patterns without a slash match the base name anywhere in
+// the tree, as in .gitignore.
+type Glob struct {
+	pattern  string
+	baseName bool
+	re       *regexp.Regexp
+}
+
+// CompileGlob parses a glob pattern.
+func CompileGlob(pattern string) (*Glob, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	p := strings.TrimPrefix(pattern, "/")
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '*':
+			if strings.HasPrefix(p[i:], "**/") {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(p[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(p[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid glob '%s': unterminated [", pattern)
+			}
+			class := p[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob '%s': %w", pattern, err)
+	}
+	return &Glob{pattern: pattern, baseName: !strings.Contains(pattern, "/"), re: re}, nil
+}
+
+// Match reports whether name, a slash separated path, matches the glob.
+func (g *Glob) Match(name string) bool {
+	if g.baseName {
+		name = path.Base(name)
+	}
+	return g.re.MatchString(name)
+}
+
+// String returns the pattern the glob was compiled from.
+func (g *Glob) String() string {
+	return g.pattern
+}
+
+// compileGlobs parses every pattern, naming the option they came from in
+// errors.
+func compileGlobs(option string, patterns []string) ([]*Glob, error) {
+	globs := make([]*Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		glob, err := CompileGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", option, err)
+		}
+		globs = append(globs, glob)
+	}
+	return globs, nil
+}
+
+// matchAny reports whether name matches one of the globs.
+func matchAny(globs []*Glob, name string) bool {
+	for _, glob := range globs {
+		if glob.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileRefGlobs parses patterns matching whole branch names. Unlike path
+// globs, a pattern without a slash does not match the last component alone,
+// so "main" does not match "feature/main".
+func compileRefGlobs(option string, patterns []string) ([]*Glob, error) {
+	globs, err := compileGlobs(option, patterns)
+	for _, glob := range globs {
+		glob.baseName = false
+	}
+	return globs, err
+}
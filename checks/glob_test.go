@@ -0,0 +1,73 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		{pattern: "*.go", match: []string{"main.go", "cmd/run.go", "a/b/c.go"}, noMatch: []string{"main.go.orig", "go"}},
+		{pattern: "/*.go", match: []string{"main.go"}, noMatch: []string{"cmd/run.go"}},
+		{pattern: "cmd/*.go", match: []string{"cmd/run.go"}, noMatch: []string{"cmd/sub/run.go", "x/cmd/run.go"}},
+		{pattern: "docs/**", match: []string{"docs/a.md", "docs/a/b/c.md"}, noMatch: []string{"docs", "src/docs/a.md"}},
+		{pattern: "**/testdata/*", match: []string{"testdata/a", "x/y/testdata/a"}, noMatch: []string{"testdata/a/b"}},
+		{pattern: "a/**/b", match: []string{"a/b", "a/x/b", "a/x/y/b"}, noMatch: []string{"a/xb"}},
+		{pattern: "file?.txt", match: []string{"file1.txt", "d/fileA.txt"}, noMatch: []string{"file10.txt", "file.txt"}},
+		{pattern: "[abc].txt", match: []string{"a.txt", "c.txt"}, noMatch: []string{"d.txt"}},
+		{pattern: "[!abc].txt", match: []string{"d.txt"}, noMatch: []string{"a.txt"}},
+		{pattern: "a+b(1).txt", match: []string{"a+b(1).txt"}, noMatch: []string{"aab1.txt"}},
+		{pattern: "Makefile", match: []string{"Makefile", "sub/Makefile"}, noMatch: []string{"Makefile.am"}},
+	}
+	for _, tt := range tests {
+		glob, err := CompileGlob(tt.pattern)
+		if err != nil {
+			t.Fatalf("CompileGlob(%q) error = %v", tt.pattern, err)
+		}
+		if glob.String() != tt.pattern {
+			t.Errorf("String() = %q, want %q", glob.String(), tt.pattern)
+		}
+		for _, name := range tt.match {
+			if !glob.Match(name) {
+				t.Errorf("%q does not match %q", tt.pattern, name)
+			}
+		}
+		for _, name := range tt.noMatch {
+			if glob.Match(name) {
+				t.Errorf("%q matches %q", tt.pattern, name)
+			}
+		}
+	}
+}
+
+func TestCompileGlobErrors(t *testing.T) {
+	if _, err := CompileGlob("[abc"); err == nil || !strings.Contains(err.Error(), "unterminated") {
+		t.Errorf("CompileGlob([abc) error = %v, want an unterminated class", err)
+	}
+	if _, err := compileGlobs("exclude", []string{"*.go", "[x"}); err == nil || !strings.HasPrefix(err.Error(), "exclude: ") {
+		t.Errorf("compileGlobs() error = %v, want it to name the option", err)
+	}
+}
+
+func TestCompileRefGlobs(t *testing.T) {
+	globs, err := compileRefGlobs("exempt", []string{"main", "release/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]bool{
+		"main":            true,
+		"feature/main":    false,
+		"release/1.0":     true,
+		"release/1.0/fix": false,
+		"hotfix/release":  false,
+	}
+	for branch, want := range tests {
+		if got := matchAny(globs, branch); got != want {
+			t.Errorf("matchAny(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package checks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(largeFilesCheck{})
+}
+
+// lfsPointerPrefix starts every Git LFS pointer file.
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/"
+
+// Images and fonts are binary but belong in most repositories.
+var defaultAllowedBinaries = []string{
+	"*.png", "*.jpg", "*.jpeg", "*.gif", "*.ico", "*.webp", "*.woff", "*.woff2", "*.ttf", "*.otf", "*.eot",
+}
+
+// largeFilesOptions are the options of the large-files check.
+type largeFilesOptions struct {
+	// MaxSize is the largest file allowed, such as 500KB or 2MB.
+	MaxSize string `yaml:"maxSize"`
+	// AllowBinary lists globs of binary files that may be committed.
+	AllowBinary []string `yaml:"allowBinary"`
+	// Exclude lists globs of files that are not checked.
+	Exclude []string `yaml:"exclude"`
+}
+
+// largeFilesPolicy is the large-files check configured by its options.
+type largeFilesPolicy struct {
+	maxSize     int64
+	allowBinary []*Glob
+	exclude     []*Glob
+}
+
+type largeFilesCheck struct{}
+
+func (largeFilesCheck) Name() string { return "large-files" }
+
+func (largeFilesCheck) Description() string {
+	return "Blocks large files, binaries outside allowed paths and files that belong in Git LFS"
+}
+
+func (largeFilesCheck) HookTypes() []string { return []string{"pre-commit", "pre-push"} }
+
+func (largeFilesCheck) ValidateOptions(opts Options) error {
+	_, err := newLargeFilesPolicy(opts)
+	return err
+}
+
+func (largeFilesCheck) Run(ctx *Context) ([]Finding, error) {
+	policy, err := newLargeFilesPolicy(ctx.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []largeFile
+	if !ctx.Staged && len(ctx.Updates) == 0 {
+		files, err = workingTreeFiles(ctx, policy)
+	} else {
+		files, err = storedFiles(ctx, policy)
+	}
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	filters, err := gitAttribute(ctx, "filter", paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	report := func(f largeFile, rule, format string, args ...any) {
+		message := fmt.Sprintf(format, args...)
+		if f.Commit != "" {
+			message += fmt.Sprintf(" (commit %.12s)", f.Commit)
+		}
+		findings = append(findings, Finding{File: f.Path, Rule: rule, Message: message})
+	}
+	for _, f := range files {
+		pointer := f.head != nil && bytes.HasPrefix(f.head, []byte(lfsPointerPrefix))
+		switch {
+		case filters[f.Path] == "lfs":
+			// The working tree holds what LFS stands in for; adding it
+			// stores the pointer
+			if !pointer && !f.workingTree {
+				report(f, "lfs", "belongs in Git LFS according to .gitattributes but is not an LFS pointer; run 'git lfs install' and add it again")
+			}
+		case pointer:
+		case f.size > policy.maxSize:
+			report(f, "max-size", "file of %s exceeds the %s limit; store it in Git LFS or outside the repository", formatSize(f.size), formatSize(policy.maxSize))
+		case f.head != nil && isBinary(f.head) && !matchAny(policy.allowBinary, f.Path):
+			report(f, "binary", "binary file outside allowBinary; build artifacts do not belong in the repository")
+		}
+	}
+	return findings, nil
+}
+
+// largeFile is a file the large-files check looks at, with its size and, for
+// files within the limit, its first bytes.
+type largeFile struct {
+	blob
+	size int64
+	head []byte
+	// workingTree is set for files read from the working tree rather than
+	// as git stores them.
+	workingTree bool
+}
+
+// storedFiles returns the staged files or those added by the pushed
+// commits, as git stores them so that LFS pointers are seen as pointers
+// rather than the content they stand for.
+func storedFiles(ctx *Context, policy *largeFilesPolicy) ([]largeFile, error) {
+	var blobs []blob
+	var err error
+	if len(ctx.Updates) > 0 && !ctx.Staged {
+		blobs, err = pushedBlobs(ctx)
+	} else {
+		blobs, err = stagedBlobs(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var checked []blob
+	var shas []string
+	for _, b := range blobs {
+		if !matchAny(policy.exclude, b.Path) {
+			checked = append(checked, b)
+			shas = append(shas, b.SHA)
+		}
+	}
+
+	sizes, err := blobSizes(ctx, shas)
+	if err != nil {
+		return nil, err
+	}
+	// Files over the limit are reported for their size alone, so their
+	// content is never read
+	var small []string
+	for _, sha := range shas {
+		if sizes[sha] <= policy.maxSize {
+			small = append(small, sha)
+		}
+	}
+	heads, err := blobHeads(ctx, small)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]largeFile, 0, len(checked))
+	for _, b := range checked {
+		files = append(files, largeFile{blob: b, size: sizes[b.SHA], head: heads[b.SHA]})
+	}
+	return files, nil
+}
+
+// workingTreeFiles returns the files given on the command line as they are
+// in the working tree, whether or not they are staged.
+func workingTreeFiles(ctx *Context, policy *largeFilesPolicy) ([]largeFile, error) {
+	var files []largeFile
+	for _, path := range ctx.Files {
+		if matchAny(policy.exclude, path) {
+			continue
+		}
+		fullPath := filepath.Join(ctx.RepoRoot, filepath.FromSlash(path))
+		info, err := os.Lstat(fullPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// git stores a symbolic link as the path it points to
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		f := largeFile{blob: blob{Path: path}, size: info.Size(), workingTree: true}
+		if f.size <= policy.maxSize {
+			if f.head, err = readHead(fullPath); err != nil {
+				return nil, err
+			}
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// readHead returns up to the first headSize bytes of a file.
+func readHead(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, headSize))
+}
+
+func newLargeFilesPolicy(opts Options) (*largeFilesPolicy, error) {
+	options := largeFilesOptions{MaxSize: "1MB", AllowBinary: defaultAllowedBinaries}
+	if err := opts.Decode(&options); err != nil {
+		return nil, err
+	}
+	maxSize, err := parseSize(options.MaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maxSize: %w", err)
+	}
+	allowBinary, err := compileGlobs("allowBinary", options.AllowBinary)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileGlobs("exclude", options.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &largeFilesPolicy{maxSize: maxSize, allowBinary: allowBinary, exclude: exclude}, nil
+}
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+}
+
+// parseSize parses a size such as 512KB or 1.5MB. Units are powers of 1024.
+func parseSize(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	s = strings.Replace(s, "IB", "B", 1)
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("'%s' is not a size such as 500KB or 2MB", value)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+func formatSize(size int64) string {
+	for _, u := range sizeUnits[:3] {
+		if size >= u.bytes {
+			return strconv.FormatFloat(float64(size)/float64(u.bytes), 'f', 1, 64) + u.suffix
+		}
+	}
+	return fmt.Sprintf("%dB", size)
+}
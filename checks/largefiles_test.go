@@ -0,0 +1,158 @@
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "500", want: 500},
+		{value: "10B", want: 10},
+		{value: "512KB", want: 512 << 10},
+		{value: "512k", want: 512 << 10},
+		{value: "1.5MB", want: 3 << 19},
+		{value: "2MiB", want: 2 << 20},
+		{value: " 1 GB ", want: 1 << 30},
+		{value: "0", wantErr: true},
+		{value: "-1MB", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "MB", wantErr: true},
+		{value: "big", wantErr: true},
+		{value: "1.5XB", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSize(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseSize(%q) = %d, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseSize(%q) = %d, %v, want %d", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		0:         "0B",
+		1023:      "1023B",
+		1 << 10:   "1.0KB",
+		3 << 19:   "1.5MB",
+		5 << 30:   "5.0GB",
+		1<<20 - 1: "1024.0KB",
+	}
+	for size, want := range tests {
+		if got := formatSize(size); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
+
+func TestLargeFilesCheck(t *testing.T) {
+	pointer := lfsPointerPrefix + "v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 5000000000\n"
+	files := map[string]string{
+		".gitattributes":   "*.psd filter=lfs diff=lfs merge=lfs -text\n",
+		"main.go":          "package main\n",
+		"big.txt":          strings.Repeat("x", 2048),
+		"design.psd":       "not a pointer\x00",
+		"video.psd":        pointer,
+		"dataset.csv":      pointer,
+		"app.exe":          "MZ\x00\x00",
+		"logo.png":         "\x89PNG\x00",
+		"vendor/blob.bin":  "\x00" + strings.Repeat("x", 2048),
+		"vendor/small.txt": "vendored\n",
+	}
+	dir := newTestRepo(t, files)
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	ctx := &Context{
+		HookType: "pre-commit",
+		RepoRoot: dir,
+		Files:    paths,
+		Staged:   true,
+		Options:  Options{"maxSize": "1KB", "exclude": []any{"vendor/**"}},
+	}
+	findings, err := largeFilesCheck{}.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, finding := range findings {
+		got = append(got, finding.Rule+"@"+finding.File)
+	}
+	sort.Strings(got)
+	want := []string{"binary@app.exe", "lfs@design.psd", "max-size@big.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Run() = %v, want %v", got, want)
+	}
+}
+
+func TestLargeFilesCheckWorkingTree(t *testing.T) {
+	dir := newTestRepo(t, map[string]string{
+		".gitattributes": "*.psd filter=lfs diff=lfs merge=lfs -text\n",
+		"big.txt":        "small when staged\n",
+		"app.exe":        "staged as text\n",
+	})
+	// run --files without staged changes looks at the files as they are now
+	for path, content := range map[string]string{
+		"big.txt":    strings.Repeat("x", 2048),
+		"app.exe":    "MZ\x00\x00",
+		"design.psd": strings.Repeat("\x00", 2048),
+		"new.bin":    "\x00untracked",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := &Context{
+		HookType: "pre-commit",
+		RepoRoot: dir,
+		Files:    []string{"app.exe", "big.txt", "design.psd", "missing.txt", "new.bin"},
+		Options:  Options{"maxSize": "1KB"},
+	}
+	findings, err := largeFilesCheck{}.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, finding := range findings {
+		got = append(got, finding.Rule+"@"+finding.File)
+	}
+	sort.Strings(got)
+	want := []string{"binary@app.exe", "binary@new.bin", "max-size@big.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Run() = %v, want %v", got, want)
+	}
+}
+
+func TestNewLargeFilesPolicyErrors(t *testing.T) {
+	for _, opts := range []Options{
+		{"maxSize": "huge"},
+		{"maxSize": "0"},
+		{"allowBinary": []any{"[x"}},
+		{"exclude": []any{"[x"}},
+		{"maxsize": "1MB"},
+	} {
+		if _, err := newLargeFilesPolicy(opts); err == nil {
+			t.Errorf("newLargeFilesPolicy(%v) accepted invalid options", opts)
+		}
+	}
+}
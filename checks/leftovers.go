@@ -0,0 +1,182 @@
+package checks
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+func init() {
+	Register(leftoversCheck{})
+}
+
+// allowDebugComment on a line stops a debug statement on it being reported,
+// for output that is meant to stay.
+const allowDebugComment = "omnihook:allow-debug"
+
+var (
+	// Conflict markers are seven characters at the start of a line, followed
+	// by the name of a side or nothing. The separator is on a line of its own.
+	conflictMarker    = regexp.MustCompile(`^(<{7}|>{7}|\|{7})(?:\s|$)`)
+	conflictSeparator = regexp.MustCompile(`^={7}$`)
+)
+
+// jsDebugStatements are the debug statements of JavaScript and the languages
+// built on it.
+var jsDebugStatements = []string{`\bconsole\.(?:log|debug|trace)\(`, `\bdebugger\b`}
+
+// defaultDebugStatements are the debug statements looked for in files with
+// each extension unless the debug option says otherwise.
+var defaultDebugStatements = map[string][]string{
+	".go":     {`\bfmt\.Print(?:f|ln)?\("DEBUG`},
+	".py":     {`\bi?pdb\.set_trace\(`, `^\s*breakpoint\(\)`},
+	".rb":     {`\bbinding\.(?:pry|irb)\b`, `^\s*byebug\b`},
+	".php":    {`\bvar_dump\(`, `\bdd\(`},
+	".js":     jsDebugStatements,
+	".jsx":    jsDebugStatements,
+	".mjs":    jsDebugStatements,
+	".cjs":    jsDebugStatements,
+	".ts":     jsDebugStatements,
+	".tsx":    jsDebugStatements,
+	".vue":    jsDebugStatements,
+	".svelte": jsDebugStatements,
+}
+
+// leftoversOptions are the options of the leftovers check.
+type leftoversOptions struct {
+	// ConflictMarkers reports merge conflict markers.
+	ConflictMarkers *bool `yaml:"conflictMarkers"`
+	// Debug maps file extensions, such as .py, to regular expressions
+	// matching debug statements in those files. An extension listed here
+	// replaces its default statements, and an empty list turns them off.
+	Debug map[string][]string `yaml:"debug"`
+	// Exclude lists globs of files that are not checked.
+	Exclude []string `yaml:"exclude"`
+}
+
+// leftoversPolicy is the leftovers check configured by its options.
+type leftoversPolicy struct {
+	conflictMarkers bool
+	debug           map[string][]*regexp.Regexp
+	exclude         []*Glob
+}
+
+type leftoversCheck struct{}
+
+func (leftoversCheck) Name() string { return "leftovers" }
+
+func (leftoversCheck) Description() string {
+	return "Blocks merge conflict markers and debug statements in added lines"
+}
+
+func (leftoversCheck) HookTypes() []string { return []string{"pre-commit", "pre-push"} }
+
+func (leftoversCheck) ValidateOptions(opts Options) error {
+	_, err := newLeftoversPolicy(opts)
+	return err
+}
+
+func (leftoversCheck) Run(ctx *Context) ([]Finding, error) {
+	policy, err := newLeftoversPolicy(ctx.Options)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := changedLines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	report := func(line addedLine, column int, rule, format string, args ...any) {
+		message := fmt.Sprintf(format, args...)
+		if line.Commit != "" {
+			message += fmt.Sprintf(" in commit %.12s", line.Commit)
+		}
+		findings = append(findings, Finding{File: line.File, Line: line.Line, Column: column, Rule: rule, Message: message})
+	}
+
+	// A line of seven equals signs is also a heading underline in Markdown
+	// and reStructuredText, so separators only count in files that have
+	// other markers too
+	var separators []addedLine
+	conflicted := make(map[string]bool)
+	for _, line := range lines {
+		if matchAny(policy.exclude, line.File) {
+			continue
+		}
+		if policy.conflictMarkers {
+			if conflictMarker.MatchString(line.Text) {
+				conflicted[line.File+"\x00"+line.Commit] = true
+				report(line, 1, "conflict-marker", "merge conflict marker %s; resolve the conflict", line.Text[:7])
+				continue
+			}
+			if conflictSeparator.MatchString(line.Text) {
+				separators = append(separators, line)
+				continue
+			}
+		}
+		if strings.Contains(line.Text, allowDebugComment) {
+			continue
+		}
+		for _, re := range policy.debug[strings.ToLower(path.Ext(line.File))] {
+			if loc := re.FindStringIndex(line.Text); loc != nil {
+				statement := strings.TrimSuffix(strings.TrimSpace(line.Text[loc[0]:loc[1]]), "(")
+				report(line, loc[0]+1, "debug-statement", "debug statement %q; remove it or add an %q comment if it is meant to stay", statement, allowDebugComment)
+				break
+			}
+		}
+	}
+	for _, line := range separators {
+		if conflicted[line.File+"\x00"+line.Commit] {
+			report(line, 1, "conflict-marker", "merge conflict marker %s; resolve the conflict", line.Text)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings, nil
+}
+
+func newLeftoversPolicy(opts Options) (*leftoversPolicy, error) {
+	var options leftoversOptions
+	if err := opts.Decode(&options); err != nil {
+		return nil, err
+	}
+	statements := make(map[string][]string, len(defaultDebugStatements))
+	for ext, patterns := range defaultDebugStatements {
+		statements[ext] = patterns
+	}
+	for ext, patterns := range options.Debug {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		statements[ext] = patterns
+	}
+
+	policy := &leftoversPolicy{conflictMarkers: true, debug: make(map[string][]*regexp.Regexp, len(statements))}
+	if options.ConflictMarkers != nil {
+		policy.conflictMarkers = *options.ConflictMarkers
+	}
+	for ext, patterns := range statements {
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid debug pattern for %s: %w", ext, err)
+			}
+			policy.debug[ext] = append(policy.debug[ext], re)
+		}
+	}
+	exclude, err := compileGlobs("exclude", options.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	policy.exclude = exclude
+	return policy, nil
+}
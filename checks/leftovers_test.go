@@ -0,0 +1,155 @@
+package checks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const conflictedFile = "<<<<<<< HEAD\nours\n=======\ntheirs\n>>>>>>> feature\n"
+
+func TestLeftoversCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		opts  Options
+		// want lists the findings, as "rule@file:line:column".
+		want []string
+	}{
+		{
+			name:  "conflict markers",
+			files: map[string]string{"a.txt": conflictedFile},
+			want:  []string{"conflict-marker@a.txt:1:1", "conflict-marker@a.txt:3:1", "conflict-marker@a.txt:5:1"},
+		},
+		{
+			name:  "diff3 base marker",
+			files: map[string]string{"a.txt": "<<<<<<< ours\na\n||||||| base\nb\n=======\nc\n>>>>>>> theirs\n"},
+			want:  []string{"conflict-marker@a.txt:1:1", "conflict-marker@a.txt:3:1", "conflict-marker@a.txt:5:1", "conflict-marker@a.txt:7:1"},
+		},
+		{
+			name:  "separator without markers is a heading",
+			files: map[string]string{"README.md": "Title\n=======\n", "a.txt": conflictedFile},
+			want:  []string{"conflict-marker@a.txt:1:1", "conflict-marker@a.txt:3:1", "conflict-marker@a.txt:5:1"},
+		},
+		{
+			name:  "markers must start the line",
+			files: map[string]string{"a.txt": " <<<<<<< HEAD\n<<<<<<<<\n========\n"},
+		},
+		{
+			name:  "conflict markers off",
+			files: map[string]string{"a.txt": conflictedFile},
+			opts:  Options{"conflictMarkers": false},
+		},
+		{
+			name:  "debug statements",
+			files: map[string]string{"app.py": "import pdb; pdb.set_trace()\nx = 1\n", "app.ts": "  console.log(x)\n", "app.go": "fmt.Println(\"DEBUG\", x)\n"},
+			want:  []string{"debug-statement@app.go:1:1", "debug-statement@app.py:1:13", "debug-statement@app.ts:1:3"},
+		},
+		{
+			name:  "allow comment",
+			files: map[string]string{"app.py": "breakpoint()  # omnihook:allow-debug\n", "app.js": "console.log(x) // omnihook:allow-debug\n"},
+		},
+		{
+			name:  "override without leading dot replaces the defaults",
+			files: map[string]string{"app.py": "pdb.set_trace()\nprint(x)\n"},
+			opts:  Options{"debug": map[string]any{"PY": []any{`\bprint\(`}}},
+			want:  []string{"debug-statement@app.py:2:1"},
+		},
+		{
+			name:  "empty list turns an extension off",
+			files: map[string]string{"app.js": "console.log(x)\n", "app.ts": "console.log(x)\n"},
+			opts:  Options{"debug": map[string]any{".js": []any{}}},
+			want:  []string{"debug-statement@app.ts:1:1"},
+		},
+		{
+			name:  "new extension",
+			files: map[string]string{"deploy.sh": "set -x\n"},
+			opts:  Options{"debug": map[string]any{"sh": []any{`^set -x$`}}},
+			want:  []string{"debug-statement@deploy.sh:1:1"},
+		},
+		{
+			name:  "excluded files",
+			files: map[string]string{"testdata/a.txt": conflictedFile, "vendor/app.js": "debugger\n"},
+			opts:  Options{"exclude": []any{"testdata/**", "vendor/**"}},
+		},
+	}
+	for _, tt := range tests {
+		dir := newTestRepo(t, tt.files)
+		var paths []string
+		for path := range tt.files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		// Staged changes are checked through their diff, other files whole
+		for _, staged := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/staged=%v", tt.name, staged), func(t *testing.T) {
+				ctx := &Context{HookType: "pre-commit", RepoRoot: dir, Files: paths, Staged: staged, Options: tt.opts}
+				findings, err := leftoversCheck{}.Run(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got []string
+				for _, finding := range findings {
+					got = append(got, fmt.Sprintf("%s@%s:%d:%d", finding.Rule, finding.File, finding.Line, finding.Column))
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Run() = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestLeftoversSeparatorsCountPerCommit(t *testing.T) {
+	dir := newTestRepo(t, map[string]string{"notes.md": "notes\n"})
+	runGit(t, dir, "commit", "--quiet", "-m", "first")
+	base := runGit(t, dir, "rev-parse", "HEAD")
+
+	commit := func(content string) string {
+		if err := os.WriteFile(filepath.Join(dir, "notes.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, "commit", "--quiet", "--all", "-m", "change")
+		return runGit(t, dir, "rev-parse", "HEAD")
+	}
+	// A heading in one commit, a conflict in the next
+	heading := commit("notes\nTitle\n=======\n")
+	conflict := commit("notes\nTitle\n=======\n<<<<<<< HEAD\na\n=======\nb\n>>>>>>> x\n")
+
+	ctx := &Context{
+		HookType: "pre-push",
+		RepoRoot: dir,
+		Updates:  []RefUpdate{{LocalRef: "refs/heads/main", LocalSHA: conflict, RemoteRef: "refs/heads/main", RemoteSHA: base}},
+	}
+	findings, err := leftoversCheck{}.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, finding := range findings {
+		got = append(got, fmt.Sprintf("%s:%d %s", finding.File, finding.Line, finding.Message))
+	}
+	marker := func(line int, text string) string {
+		return fmt.Sprintf("notes.md:%d merge conflict marker %s; resolve the conflict in commit %.12s", line, text, conflict)
+	}
+	want := []string{marker(4, "<<<<<<<"), marker(6, "======="), marker(8, ">>>>>>>")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Run() = %q, want %q (heading added in %.12s)", got, want, heading)
+	}
+}
+
+func TestNewLeftoversPolicyErrors(t *testing.T) {
+	for _, opts := range []Options{
+		{"debug": map[string]any{".py": []any{"("}}},
+		{"exclude": []any{"[x"}},
+		{"conflictMarkers": "yes please"},
+		{"debugs": map[string]any{}},
+	} {
+		if _, err := newLeftoversPolicy(opts); err == nil {
+			t.Errorf("newLeftoversPolicy(%v) accepted invalid options", opts)
+		}
+	}
+}
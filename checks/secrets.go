@@ -0,0 +1,483 @@
+package checks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	Register(secretsCheck{})
+}
+
+const (
+	// defaultSecretsBaseline is where the baseline is kept unless the
+	// baseline option says otherwise, relative to the repository root.
+	defaultSecretsBaseline = ".omnihook-secrets-baseline.json"
+	// allowSecretComment on a line stops it being reported, for test
+	// fixtures and values that only look like secrets.
+	allowSecretComment = "omnihook:allow-secret"
+	// highEntropyRule reports strings that look random enough to be
+	// secrets without matching any rule.
+	highEntropyRule = "high-entropy-string"
+)
+
+// secretRule detects one kind of secret.
+type secretRule struct {
+	ID          string `yaml:"id"`
+	Description string `yaml:"description"`
+	Pattern     string `yaml:"pattern"`
+	// Group is the submatch holding the secret; the whole match by default.
+	Group int `yaml:"group"`
+	// Entropy is the Shannon entropy, in bits per character, the secret
+	// must reach, to tell real secrets from placeholders.
+	Entropy float64 `yaml:"entropy"`
+
+	re *regexp.Regexp
+}
+
+var defaultSecretRules = []secretRule{
+	{ID: "private-key", Description: "private key", Pattern: `-----BEGIN[ A-Z0-9_-]*PRIVATE KEY( BLOCK)?-----`},
+	{ID: "aws-access-key-id", Description: "AWS access key ID", Pattern: `\b((?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16})\b`, Group: 1},
+	{ID: "aws-secret-access-key", Description: "AWS secret access key", Pattern: `(?i)aws.{0,20}?(?:secret|private).{0,20}?[=:]\s*["']?([A-Za-z0-9/+]{40})\b`, Group: 1, Entropy: 3.5},
+	{ID: "gcp-service-account", Description: "Google Cloud service account key", Pattern: `"private_key_id"\s*:\s*"([0-9a-f]{40})"`, Group: 1},
+	{ID: "google-api-key", Description: "Google API key", Pattern: `\bAIza[0-9A-Za-z_-]{35}\b`},
+	{ID: "azure-storage-key", Description: "Azure storage account key", Pattern: `AccountKey=([A-Za-z0-9+/]{86}==)`, Group: 1},
+	{ID: "github-token", Description: "GitHub token", Pattern: `\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{82})\b`},
+	{ID: "gitlab-token", Description: "GitLab token", Pattern: `\bglpat-[A-Za-z0-9_-]{20}\b`},
+	{ID: "slack-token", Description: "Slack token", Pattern: `\bxox[abposr]-[A-Za-z0-9-]{10,}\b`},
+	{ID: "slack-webhook", Description: "Slack webhook URL", Pattern: `https://hooks\.slack\.com/services/T[A-Za-z0-9_]+/B[A-Za-z0-9_]+/[A-Za-z0-9_]+`},
+	{ID: "stripe-secret-key", Description: "Stripe secret key", Pattern: `\b[rs]k_live_[A-Za-z0-9]{24,}\b`},
+	{ID: "npm-token", Description: "npm access token", Pattern: `\bnpm_[A-Za-z0-9]{36}\b`},
+	{ID: "jwt", Description: "JSON Web Token", Pattern: `\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`},
+}
+
+// Values that are never reported: documentation examples, placeholders and
+// references to variables.
+var defaultSecretAllowlist = []string{
+	`(?i)example|placeholder|dummy|changeme|redacted`,
+	`^\$\{?[A-Za-z_][A-Za-z0-9_]*\}?$`,
+	`^<[^>]*>$`,
+}
+
+// Lockfiles are full of checksums that look random.
+var defaultSecretExcludes = []string{
+	"go.sum", "*.lock", "package-lock.json", "pnpm-lock.yaml", "npm-shrinkwrap.json", defaultSecretsBaseline,
+}
+
+// Strings that may be secrets: quoted values, and values assigned to
+// something named like a secret.
+var (
+	quotedCandidate   = regexp.MustCompile(`["'\x60]([A-Za-z0-9+/=_.~-]{8,})["'\x60]`)
+	assignedCandidate = regexp.MustCompile(`(?i)(?:secret|token|passw(?:or)?d|pwd|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)["']?\s*(?::=|[:=])\s*["']?([^\s"'\x60,;]{8,})`)
+	hexString         = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+)
+
+// hexDigestLengths are the lengths of hexadecimal MD5, SHA-1 and SHA-256
+// digests, which lockfiles, checksums and commit references are full of.
+// Quoted ones are only reported when assigned to something named like a
+// secret.
+var hexDigestLengths = []int{32, 40, 64}
+
+// secretsOptions are the options of the secrets check.
+type secretsOptions struct {
+	// Rules is a rule file adding rules, excludes and allowlist entries,
+	// relative to the repository root.
+	Rules string `yaml:"rules"`
+	// Disable lists rules that are not checked, including high-entropy-string.
+	Disable []string `yaml:"disable"`
+	// Exclude lists globs of files that are not checked.
+	Exclude []string `yaml:"exclude"`
+	// Allowlist lists patterns of values that are not secrets.
+	Allowlist []string `yaml:"allowlist"`
+	// Baseline is the file recording known findings, relative to the
+	// repository root.
+	Baseline string `yaml:"baseline"`
+	// Entropy and HexEntropy are the bits per character from which strings
+	// count as random, for strings in general and hexadecimal ones.
+	Entropy    *float64 `yaml:"entropy"`
+	HexEntropy *float64 `yaml:"hexEntropy"`
+	// MinLength is the length from which strings are checked for entropy.
+	MinLength int `yaml:"minLength"`
+}
+
+// secretRuleFile is the format of the rules option's file.
+type secretRuleFile struct {
+	Rules     []secretRule `yaml:"rules"`
+	Exclude   []string     `yaml:"exclude"`
+	Allowlist []string     `yaml:"allowlist"`
+}
+
+// secretsBaseline records findings a repository already had when the check
+// was adopted. Secrets are identified by their hash rather than their line,
+// which changes as the file is edited.
+type secretsBaseline struct {
+	Findings []baselineSecret `json:"findings"`
+}
+
+type baselineSecret struct {
+	File        string `json:"file"`
+	Rule        string `json:"rule"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// secretScanner is the secrets check configured by its options.
+type secretScanner struct {
+	rules      []secretRule
+	exclude    []*Glob
+	allowlist  []*regexp.Regexp
+	entropy    float64
+	hexEntropy float64
+	minLength  int
+	disabled   []string
+	rulesFile  string
+	baseline   string
+}
+
+// secretMatch is a possible secret found on a line.
+type secretMatch struct {
+	line   addedLine
+	rule   *secretRule
+	secret string
+	column int
+}
+
+type secretsCheck struct{}
+
+func (secretsCheck) Name() string { return "secrets" }
+
+func (secretsCheck) Description() string {
+	return "Rejects private keys, cloud credentials, tokens and other high-entropy strings"
+}
+
+func (secretsCheck) HookTypes() []string { return []string{"pre-commit", "pre-push"} }
+
+func (secretsCheck) ValidateOptions(opts Options) error {
+	_, err := newSecretScanner(opts)
+	return err
+}
+
+func (secretsCheck) Run(ctx *Context) ([]Finding, error) {
+	scanner, err := newSecretScanner(ctx.Options)
+	if err != nil {
+		return nil, err
+	}
+	if err := scanner.loadRuleFile(ctx.RepoRoot); err != nil {
+		return nil, err
+	}
+	known, err := scanner.readBaseline(ctx.RepoRoot)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := scanner.scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, match := range matches {
+		if known[match.fingerprint()] {
+			continue
+		}
+		message := "possible " + match.rule.Description
+		if match.line.Commit != "" {
+			message += fmt.Sprintf(" in commit %.12s", match.line.Commit)
+		}
+		findings = append(findings, Finding{
+			File:    match.line.File,
+			Line:    match.line.Line,
+			Column:  match.column,
+			Rule:    match.rule.ID,
+			Message: message,
+		})
+	}
+	if len(findings) > 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("if these are not secrets, add a %q comment to the line or record them with omnihook baseline", allowSecretComment),
+		})
+	}
+	return findings, nil
+}
+
+// WriteBaseline records every secret currently in ctx.Files, so that only
+// new ones are reported.
+func (secretsCheck) WriteBaseline(ctx *Context) (string, int, error) {
+	scanner, err := newSecretScanner(ctx.Options)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := scanner.loadRuleFile(ctx.RepoRoot); err != nil {
+		return "", 0, err
+	}
+	matches, err := scanner.scan(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+
+	baseline := secretsBaseline{Findings: []baselineSecret{}}
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		key := match.fingerprint()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		baseline.Findings = append(baseline.Findings, baselineSecret{
+			File:        match.line.File,
+			Rule:        match.rule.ID,
+			Fingerprint: secretFingerprint(match.secret),
+		})
+	}
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return "", 0, err
+	}
+	path := filepath.Join(ctx.RepoRoot, filepath.FromSlash(scanner.baseline))
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", 0, fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return path, len(baseline.Findings), nil
+}
+
+func newSecretScanner(opts Options) (*secretScanner, error) {
+	var options secretsOptions
+	if err := opts.Decode(&options); err != nil {
+		return nil, err
+	}
+	scanner := &secretScanner{
+		entropy:    4.5,
+		hexEntropy: 3.0,
+		minLength:  20,
+		disabled:   options.Disable,
+		rulesFile:  options.Rules,
+		baseline:   defaultSecretsBaseline,
+	}
+	if options.Entropy != nil {
+		scanner.entropy = *options.Entropy
+	}
+	if options.HexEntropy != nil {
+		scanner.hexEntropy = *options.HexEntropy
+	}
+	if options.MinLength > 0 {
+		scanner.minLength = options.MinLength
+	}
+	if options.Baseline != "" {
+		scanner.baseline = options.Baseline
+	}
+	if err := scanner.add(defaultSecretRules, append(slices.Clone(defaultSecretExcludes), options.Exclude...),
+		append(slices.Clone(defaultSecretAllowlist), options.Allowlist...)); err != nil {
+		return nil, err
+	}
+	return scanner, nil
+}
+
+// loadRuleFile adds the rules of the rules option's file.
+func (s *secretScanner) loadRuleFile(repoRoot string) error {
+	if s.rulesFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(s.rulesFile)))
+	if err != nil {
+		return fmt.Errorf("failed to read rule file: %w", err)
+	}
+	var file secretRuleFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("invalid rule file %s: %w", s.rulesFile, err)
+	}
+	if err := s.add(file.Rules, file.Exclude, file.Allowlist); err != nil {
+		return fmt.Errorf("invalid rule file %s: %w", s.rulesFile, err)
+	}
+	return nil
+}
+
+// add compiles rules, excludes and allowlist entries into the scanner.
+func (s *secretScanner) add(rules []secretRule, exclude, allowlist []string) error {
+	for _, rule := range rules {
+		if rule.ID == "" || rule.Pattern == "" {
+			return fmt.Errorf("rules need an id and a pattern")
+		}
+		if slices.Contains(s.disabled, rule.ID) {
+			continue
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("rule %s: invalid pattern: %w", rule.ID, err)
+		}
+		if rule.Group < 0 || rule.Group > re.NumSubexp() {
+			return fmt.Errorf("rule %s: pattern has no group %d", rule.ID, rule.Group)
+		}
+		if rule.Description == "" {
+			rule.Description = rule.ID
+		}
+		rule.re = re
+		s.rules = append(s.rules, rule)
+	}
+	globs, err := compileGlobs("exclude", exclude)
+	if err != nil {
+		return err
+	}
+	s.exclude = append(s.exclude, globs...)
+	for _, pattern := range allowlist {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("allowlist: invalid pattern: %w", err)
+		}
+		s.allowlist = append(s.allowlist, re)
+	}
+	return nil
+}
+
+// readBaseline returns the fingerprints of the findings in the baseline.
+func (s *secretScanner) readBaseline(repoRoot string) (map[string]bool, error) {
+	data, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(s.baseline)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var baseline secretsBaseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", s.baseline, err)
+	}
+	known := make(map[string]bool, len(baseline.Findings))
+	for _, finding := range baseline.Findings {
+		known[finding.File+"\x00"+finding.Rule+"\x00"+finding.Fingerprint] = true
+	}
+	return known, nil
+}
+
+// scan returns the possible secrets on the lines ctx selects.
+func (s *secretScanner) scan(ctx *Context) ([]secretMatch, error) {
+	lines, err := changedLines(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanLines(lines), nil
+}
+
+// scanLines returns the possible secrets on lines.
+func (s *secretScanner) scanLines(lines []addedLine) []secretMatch {
+	entropyRule := &secretRule{ID: highEntropyRule, Description: "secret (high-entropy string)"}
+	checkEntropy := !slices.Contains(s.disabled, highEntropyRule)
+
+	var matches []secretMatch
+	for _, line := range lines {
+		if matchAny(s.exclude, line.File) || strings.Contains(line.Text, allowSecretComment) {
+			continue
+		}
+		var covered [][2]int
+		for i := range s.rules {
+			rule := &s.rules[i]
+			for _, loc := range rule.re.FindAllStringSubmatchIndex(line.Text, -1) {
+				start, end := loc[2*rule.Group], loc[2*rule.Group+1]
+				if start < 0 {
+					continue
+				}
+				covered = append(covered, [2]int{loc[0], loc[1]})
+				secret := line.Text[start:end]
+				if rule.Entropy > 0 && shannonEntropy(secret) < rule.Entropy || s.allowed(secret) {
+					continue
+				}
+				matches = append(matches, secretMatch{line: line, rule: rule, secret: secret, column: start + 1})
+			}
+		}
+		if !checkEntropy {
+			continue
+		}
+		for _, candidates := range []*regexp.Regexp{quotedCandidate, assignedCandidate} {
+			for _, loc := range candidates.FindAllStringSubmatchIndex(line.Text, -1) {
+				start, end := loc[2], loc[3]
+				secret := line.Text[start:end]
+				if len(secret) < s.minLength || overlaps(covered, start, end) || s.allowed(secret) || !s.random(secret) {
+					continue
+				}
+				if candidates == quotedCandidate && isHexDigest(secret) {
+					continue
+				}
+				covered = append(covered, [2]int{start, end})
+				matches = append(matches, secretMatch{line: line, rule: entropyRule, secret: secret, column: start + 1})
+			}
+		}
+	}
+	return matches
+}
+
+// isHexDigest reports whether value has the length and alphabet of a
+// hexadecimal digest.
+func isHexDigest(value string) bool {
+	return slices.Contains(hexDigestLengths, len(value)) && hexString.MatchString(value)
+}
+
+func (s *secretScanner) allowed(secret string) bool {
+	for _, re := range s.allowlist {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// random reports whether a string's entropy is high enough for a secret.
+// Hexadecimal strings can only reach 4 bits per character, so they have a
+// threshold of their own.
+func (s *secretScanner) random(value string) bool {
+	if strings.Contains(value, "://") {
+		return false
+	}
+	threshold := s.entropy
+	if hexString.MatchString(value) {
+		threshold = s.hexEntropy
+	}
+	return threshold > 0 && shannonEntropy(value) >= threshold
+}
+
+func (m secretMatch) fingerprint() string {
+	return m.line.File + "\x00" + m.rule.ID + "\x00" + secretFingerprint(m.secret)
+}
+
+func secretFingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func overlaps(ranges [][2]int, start, end int) bool {
+	for _, r := range ranges {
+		if start < r[1] && r[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,405 @@
+package checks
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	Register(syntaxCheck{})
+}
+
+// syntaxFormats are the formats the syntax check parses, in the order a
+// file's globs are tried.
+var syntaxFormats = []string{"yaml", "json", "toml", "xml"}
+
+// syntaxParsers parse a document of each format, returning its problems
+// with their line and column.
+var syntaxParsers = map[string]func(data []byte, duplicateKeys bool) []Finding{
+	"yaml": parseYAML,
+	"json": parseJSON,
+	"toml": parseTOML,
+	"xml":  parseXML,
+}
+
+var defaultSyntaxGlobs = map[string][]string{
+	"yaml": {"*.yaml", "*.yml"},
+	"json": {"*.json"},
+	"toml": {"*.toml"},
+	"xml":  {"*.xml"},
+}
+
+// defaultSyntaxExclude are files that look like one of the formats but are
+// not: JSON with comments, as editors and the TypeScript compiler accept,
+// and Helm chart templates.
+var defaultSyntaxExclude = []string{
+	"tsconfig*.json", "jsconfig*.json", "devcontainer.json", ".devcontainer.json", "**/.vscode/*.json", "**/templates/**",
+}
+
+// yamlErrorLine extracts the line yaml.v3 reports most errors at.
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// syntaxOptions are the options of the syntax check.
+type syntaxOptions struct {
+	// Formats map yaml, json, toml and xml to globs of the files parsed as
+	// that format. A format listed here replaces its default globs.
+	Formats map[string][]string `yaml:"formats"`
+	// DuplicateKeys reports keys defined twice in a YAML mapping or a JSON
+	// object. TOML does not allow them at all.
+	DuplicateKeys bool `yaml:"duplicateKeys"`
+	// Exclude lists globs of files that are not checked.
+	Exclude []string `yaml:"exclude"`
+}
+
+// syntaxPolicy is the syntax check configured by its options.
+type syntaxPolicy struct {
+	formats       map[string][]*Glob
+	duplicateKeys bool
+	exclude       []*Glob
+}
+
+type syntaxCheck struct{}
+
+func (syntaxCheck) Name() string { return "syntax" }
+
+func (syntaxCheck) Description() string {
+	return "Checks that YAML, JSON, TOML and XML files parse, optionally rejecting duplicate keys"
+}
+
+func (syntaxCheck) HookTypes() []string { return []string{"pre-commit", "pre-push"} }
+
+func (syntaxCheck) ValidateOptions(opts Options) error {
+	_, err := newSyntaxPolicy(opts)
+	return err
+}
+
+func (syntaxCheck) Run(ctx *Context) ([]Finding, error) {
+	policy, err := newSyntaxPolicy(ctx.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files are parsed as they are staged or committed, which is not
+	// necessarily what is in the working tree
+	var blobs []blob
+	if len(ctx.Updates) > 0 && !ctx.Staged {
+		blobs, err = pushedBlobs(ctx)
+	} else {
+		blobs, err = stagedBlobs(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	formats := make(map[string]string)
+	var checked []blob
+	var paths, shas []string
+	for _, b := range blobs {
+		format := policy.format(b.Path)
+		if format == "" {
+			continue
+		}
+		formats[b.Path] = format
+		checked = append(checked, b)
+		paths = append(paths, b.Path)
+		shas = append(shas, b.SHA)
+	}
+	// Files kept in Git LFS are only pointers here
+	filters, err := gitAttribute(ctx, "filter", paths)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := blobContents(ctx, shas)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, b := range checked {
+		content, ok := contents[b.SHA]
+		if !ok || filters[b.Path] == "lfs" {
+			continue
+		}
+		for _, finding := range syntaxParsers[formats[b.Path]](content, policy.duplicateKeys) {
+			finding.File = b.Path
+			if b.Commit != "" {
+				finding.Message += fmt.Sprintf(" in commit %.12s", b.Commit)
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+func newSyntaxPolicy(opts Options) (*syntaxPolicy, error) {
+	options := syntaxOptions{Exclude: defaultSyntaxExclude}
+	if err := opts.Decode(&options); err != nil {
+		return nil, err
+	}
+	policy := &syntaxPolicy{formats: make(map[string][]*Glob), duplicateKeys: options.DuplicateKeys}
+	for format := range options.Formats {
+		if _, ok := syntaxParsers[format]; !ok {
+			return nil, fmt.Errorf("unknown format '%s' in formats, expected yaml, json, toml or xml", format)
+		}
+	}
+	for _, format := range syntaxFormats {
+		patterns, ok := options.Formats[format]
+		if !ok {
+			patterns = defaultSyntaxGlobs[format]
+		}
+		globs, err := compileGlobs("formats."+format, patterns)
+		if err != nil {
+			return nil, err
+		}
+		policy.formats[format] = globs
+	}
+	exclude, err := compileGlobs("exclude", options.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	policy.exclude = exclude
+	return policy, nil
+}
+
+// format returns the format the file is parsed as, or "" when it is not
+// checked.
+func (p *syntaxPolicy) format(file string) string {
+	if matchAny(p.exclude, file) {
+		return ""
+	}
+	for _, format := range syntaxFormats {
+		if matchAny(p.formats[format], file) {
+			return format
+		}
+	}
+	return ""
+}
+
+// parseYAML parses every document of a YAML stream. The first syntax error
+// ends the stream, as the parser cannot carry on after it.
+func parseYAML(data []byte, duplicateKeys bool) []Finding {
+	var findings []Finding
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return findings
+		}
+		if err != nil {
+			finding := Finding{Rule: "yaml", Message: "invalid YAML: " + strings.TrimPrefix(err.Error(), "yaml: ")}
+			if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+				finding.Line, _ = strconv.Atoi(match[1])
+				finding.Message = "invalid YAML: " + match[2]
+			}
+			return append(findings, finding)
+		}
+		if duplicateKeys {
+			findings = append(findings, yamlDuplicateKeys(&document)...)
+		}
+	}
+}
+
+// yamlDuplicateKeys reports keys defined twice in the mappings of a YAML
+// document. Merge keys may repeat, and aliases are not followed.
+func yamlDuplicateKeys(node *yaml.Node) []Finding {
+	var findings []Finding
+	if node.Kind == yaml.MappingNode {
+		seen := make(map[string]*yaml.Node)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode || key.Value == "<<" {
+				continue
+			}
+			if first, ok := seen[key.Value]; ok {
+				findings = append(findings, Finding{
+					Line:    key.Line,
+					Column:  key.Column,
+					Rule:    "duplicate-key",
+					Message: fmt.Sprintf("duplicate key %q, first defined on line %d", key.Value, first.Line),
+				})
+				continue
+			}
+			seen[key.Value] = key
+		}
+	}
+	if node.Kind != yaml.AliasNode {
+		for _, child := range node.Content {
+			findings = append(findings, yamlDuplicateKeys(child)...)
+		}
+	}
+	return findings
+}
+
+// parseJSON parses a JSON document, which must be a single value.
+func parseJSON(data []byte, duplicateKeys bool) []Finding {
+	var raw json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		finding := Finding{Rule: "json", Message: "invalid JSON: " + err.Error()}
+		var syntaxErr *json.SyntaxError
+		// The offset counts the byte the error was found at
+		if errors.As(err, &syntaxErr) {
+			finding.Line, finding.Column = offsetPosition(data, syntaxErr.Offset-1)
+		}
+		return []Finding{finding}
+	}
+	if !duplicateKeys {
+		return nil
+	}
+	return jsonDuplicateKeys(data)
+}
+
+// jsonObject tracks the keys of an object while its tokens are read.
+type jsonObject struct {
+	keys map[string]int64
+	// key is set when the next token is a key rather than a value.
+	key bool
+}
+
+// jsonDuplicateKeys reports keys defined twice in an object of a valid JSON
+// document.
+func jsonDuplicateKeys(data []byte) []Finding {
+	var findings []Finding
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	// Arrays are on the stack as nil
+	var stack []*jsonObject
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return findings
+		}
+		var top *jsonObject
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if top != nil && top.key {
+			if key, ok := token.(string); ok {
+				top.key = false
+				// The offset is just past the key's closing quote
+				start := jsonStringStart(data, decoder.InputOffset())
+				if first, ok := top.keys[key]; ok {
+					line, column := offsetPosition(data, start)
+					firstLine, _ := offsetPosition(data, first)
+					findings = append(findings, Finding{
+						Line:    line,
+						Column:  column,
+						Rule:    "duplicate-key",
+						Message: fmt.Sprintf("duplicate key %q, first defined on line %d", key, firstLine),
+					})
+				} else {
+					top.keys[key] = start
+				}
+				continue
+			}
+		}
+		switch token {
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if top != nil {
+			top.key = true
+		}
+		switch token {
+		case json.Delim('{'):
+			stack = append(stack, &jsonObject{keys: make(map[string]int64), key: true})
+		case json.Delim('['):
+			stack = append(stack, nil)
+		}
+	}
+}
+
+// jsonStringStart returns the offset of the opening quote of the string
+// whose closing quote is just before end.
+func jsonStringStart(data []byte, end int64) int64 {
+	for i := end - 2; i >= 0; i-- {
+		if data[i] != '"' {
+			continue
+		}
+		backslashes := 0
+		for j := i - 1; j >= 0 && data[j] == '\\'; j-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// offsetPosition converts a byte offset into data to a line and a column
+// counted in characters, both starting at 1.
+func offsetPosition(data []byte, offset int64) (int, int) {
+	offset = min(max(offset, 0), int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := utf8.RuneCount(before[bytes.LastIndexByte(before, '\n')+1:]) + 1
+	return line, column
+}
+
+// parseTOML parses a TOML document. Duplicate keys are always an error in
+// TOML.
+func parseTOML(data []byte, _ bool) []Finding {
+	var document map[string]any
+	err := toml.Unmarshal(data, &document)
+	if err == nil {
+		return nil
+	}
+	finding := Finding{Rule: "toml", Message: "invalid TOML: " + strings.TrimPrefix(err.Error(), "toml: ")}
+	var decodeErr *toml.DecodeError
+	if errors.As(err, &decodeErr) {
+		finding.Line, finding.Column = decodeErr.Position()
+	}
+	return []Finding{finding}
+}
+
+// parseXML parses an XML document, which must have a single root element.
+func parseXML(data []byte, _ bool) []Finding {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// Only the syntax is checked, which other encodings share with UTF-8
+	// as far as the parser is concerned
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	roots, depth := 0, 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			line, column := decoder.InputPos()
+			message := err.Error()
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				line, message = syntaxErr.Line, syntaxErr.Msg
+			}
+			return []Finding{{Line: line, Column: column, Rule: "xml", Message: "invalid XML: " + strings.TrimPrefix(message, "xml: ")}}
+		}
+		switch token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				if roots++; roots == 2 {
+					line, column := decoder.InputPos()
+					return []Finding{{Line: line, Column: column, Rule: "xml", Message: "invalid XML: more than one root element"}}
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	if roots == 0 {
+		return []Finding{{Rule: "xml", Message: "invalid XML: no root element"}}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package checks
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSyntaxParsers(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        string
+		duplicateKeys bool
+		content       string
+		// want lists the rules reported, as "rule@line:column".
+		want []string
+	}{
+		{name: "YAML", format: "yaml", content: "a: 1\nb: [1, 2]\n"},
+		{name: "empty YAML", format: "yaml", content: ""},
+		{name: "YAML documents", format: "yaml", content: "a: 1\n---\nb: 2\n"},
+		{name: "invalid YAML", format: "yaml", content: "a: 1\nb: c: d\n", want: []string{"yaml@2:0"}},
+		{name: "invalid later YAML document", format: "yaml", content: "a: 1\n---\nb: 2\nc: d: e\n", want: []string{"yaml@4:0"}},
+		{name: "YAML duplicate keys", format: "yaml", duplicateKeys: true, content: "a: 1\nb:\n  c: 1\n  c: 2\na: 3\n", want: []string{"duplicate-key@5:1", "duplicate-key@4:3"}},
+		{name: "YAML duplicate keys allowed", format: "yaml", content: "a: 1\na: 2\n"},
+		{name: "YAML duplicate keys by document", format: "yaml", duplicateKeys: true, content: "a: 1\n---\na: 2\n"},
+		{name: "YAML merge keys", format: "yaml", duplicateKeys: true, content: "x: &x {a: 1}\ny: &y {b: 1}\nz:\n  <<: *x\n  <<: *y\n"},
+		{name: "JSON", format: "json", content: `{"a": [1, {"b": null}]}`},
+		{name: "invalid JSON", format: "json", content: "{\n  \"a\": 1,\n}\n", want: []string{"json@3:1"}},
+		{name: "truncated JSON", format: "json", content: `{"a": 1`, want: []string{"json@1:7"}},
+		{name: "JSON duplicate keys", format: "json", duplicateKeys: true, content: "{\n  \"a\": 1,\n  \"b\": {\"a\": 2, \"a\": 3},\n  \"a\": 4\n}\n", want: []string{"duplicate-key@3:17", "duplicate-key@4:3"}},
+		{name: "JSON duplicate keys in arrays", format: "json", duplicateKeys: true, content: `[{"a": 1}, {"a": 2}]`},
+		{name: "JSON escaped keys", format: "json", duplicateKeys: true, content: `{"a\"": 1, "a\\": 2, "a\"": 3}`, want: []string{"duplicate-key@1:22"}},
+		{name: "TOML", format: "toml", content: "a = 1\n[b]\nc = \"d\"\n"},
+		{name: "invalid TOML", format: "toml", content: "a = 1\nb = \n", want: []string{"toml@2:5"}},
+		// The TOML parser does not report where a key is defined again
+		{name: "TOML duplicate keys", format: "toml", content: "a = 1\na = 2\n", want: []string{"toml@0:0"}},
+		{name: "XML", format: "xml", content: "<?xml version=\"1.0\"?>\n<a><b/></a>\n"},
+		{name: "XML in another encoding", format: "xml", content: "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<a/>\n"},
+		{name: "invalid XML", format: "xml", content: "<a>\n<b></a>\n", want: []string{"xml@2:8"}},
+		{name: "several XML roots", format: "xml", content: "<a/>\n<b/>\n", want: []string{"xml@2:5"}},
+		{name: "no XML root", format: "xml", content: "<!-- empty -->\n", want: []string{"xml@0:0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, finding := range syntaxParsers[tt.format]([]byte(tt.content), tt.duplicateKeys) {
+				got = append(got, fmt.Sprintf("%s@%d:%d", finding.Rule, finding.Line, finding.Column))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse %s %q = %v, want %v", tt.format, tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOffsetPosition(t *testing.T) {
+	data := []byte("ab\ncé\nd")
+	tests := []struct {
+		offset       int64
+		line, column int
+	}{
+		{-1, 1, 1},
+		{0, 1, 1},
+		{2, 1, 3},
+		{3, 2, 1},
+		{6, 2, 3},
+		{7, 3, 1},
+		{100, 3, 2},
+	}
+	for _, tt := range tests {
+		if line, column := offsetPosition(data, tt.offset); line != tt.line || column != tt.column {
+			t.Errorf("offsetPosition(%d) = %d:%d, want %d:%d", tt.offset, line, column, tt.line, tt.column)
+		}
+	}
+}
+
+func TestSyntaxPolicyFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		file string
+		want string
+	}{
+		{name: "YAML", file: "ci/config.yml", want: "yaml"},
+		{name: "JSON", file: "package.json", want: "json"},
+		{name: "TOML", file: "Cargo.toml", want: "toml"},
+		{name: "XML", file: "pom.xml", want: "xml"},
+		{name: "other", file: "main.go"},
+		{name: "JSON with comments", file: "tsconfig.build.json"},
+		{name: "Helm template", file: "chart/templates/deployment.yaml"},
+		{name: "custom format", opts: Options{"formats": map[string]any{"json": []any{"*.json", "*.jsonc"}}}, file: ".eslintrc.jsonc", want: "json"},
+		{name: "custom format keeps the others", opts: Options{"formats": map[string]any{"json": []any{"*.jsonc"}}}, file: "a.yaml", want: "yaml"},
+		{name: "custom format replaces its defaults", opts: Options{"formats": map[string]any{"json": []any{"*.jsonc"}}}, file: "a.json"},
+		{name: "custom exclude", opts: Options{"exclude": []any{"fixtures/**"}}, file: "fixtures/bad.json"},
+		{name: "custom exclude replaces the default", opts: Options{"exclude": []any{"fixtures/**"}}, file: "tsconfig.json", want: "json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := newSyntaxPolicy(tt.opts)
+			if err != nil {
+				t.Fatalf("newSyntaxPolicy() error = %v", err)
+			}
+			if got := policy.format(tt.file); got != tt.want {
+				t.Errorf("format(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSyntaxPolicyErrors(t *testing.T) {
+	tests := []struct {
+		opts    Options
+		wantErr string
+	}{
+		{Options{"formats": map[string]any{"ini": []any{"*.ini"}}}, "unknown format 'ini'"},
+		{Options{"formats": map[string]any{"yaml": []any{"[x"}}}, "formats.yaml"},
+		{Options{"duplicates": true}, "unknown option 'duplicates'"},
+	}
+	for _, tt := range tests {
+		if _, err := newSyntaxPolicy(tt.opts); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("newSyntaxPolicy(%v) error = %v, want one containing %q", tt.opts, err, tt.wantErr)
+		}
+	}
+}
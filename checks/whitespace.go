@@ -0,0 +1,415 @@
+package checks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+func init() {
+	Register(whitespaceCheck{})
+}
+
+// maxWhitespaceFindings caps the findings of each rule reported per file;
+// the rest are counted in a last finding.
+const maxWhitespaceFindings = 10
+
+// whitespaceSettings say what whitespace files must have. Unset fields keep
+// the value of the settings applied before.
+type whitespaceSettings struct {
+	// TrailingWhitespace rejects spaces and tabs at the end of lines.
+	TrailingWhitespace *bool `yaml:"trailingWhitespace"`
+	// FinalNewline requires files to end with a line ending.
+	FinalNewline *bool `yaml:"finalNewline"`
+	// LineEndings is lf or crlf to require those line endings, consistent
+	// to only reject files mixing them, or none to allow anything.
+	LineEndings string `yaml:"lineEndings"`
+	// Indent is tabs or spaces to require indentation with them, or none
+	// to allow either.
+	Indent string `yaml:"indent"`
+	// TabWidth is how many spaces a tab stands for when converting
+	// indentation.
+	TabWidth int `yaml:"tabWidth"`
+}
+
+// whitespaceRule applies settings to the files matching its globs.
+type whitespaceRule struct {
+	Files              []string `yaml:"files"`
+	whitespaceSettings `yaml:",inline"`
+}
+
+// whitespaceOptions are the options of the whitespace check. The settings
+// apply to every file, then each rule matching the file in turn.
+type whitespaceOptions struct {
+	whitespaceSettings `yaml:",inline"`
+	// Mode is check to report problems or fix to also correct them in the
+	// working tree.
+	Mode  string           `yaml:"mode"`
+	Rules []whitespaceRule `yaml:"rules"`
+	// Exclude lists globs of files that are not checked.
+	Exclude []string `yaml:"exclude"`
+}
+
+// whitespaceStyle is the whitespace a file must have.
+type whitespaceStyle struct {
+	trailingWhitespace bool
+	finalNewline       bool
+	lineEndings        string
+	indent             string
+	tabWidth           int
+}
+
+// compiledWhitespaceRule is a whitespaceRule with its globs compiled.
+type compiledWhitespaceRule struct {
+	files    []*Glob
+	settings whitespaceSettings
+}
+
+// whitespacePolicy is the whitespace check configured by its options.
+type whitespacePolicy struct {
+	fix     bool
+	base    whitespaceStyle
+	rules   []compiledWhitespaceRule
+	exclude []*Glob
+}
+
+// Two trailing spaces break a line in Markdown.
+var defaultWhitespaceRules = []whitespaceRule{
+	{Files: []string{"*.md", "*.markdown"}, whitespaceSettings: whitespaceSettings{TrailingWhitespace: new(bool)}},
+}
+
+// Patches need their whitespace exactly as it is.
+var defaultWhitespaceExclude = []string{"*.patch", "*.diff"}
+
+type whitespaceCheck struct{}
+
+func (whitespaceCheck) Name() string { return "whitespace" }
+
+func (whitespaceCheck) Description() string {
+	return "Checks or fixes trailing whitespace, final newlines, line endings and indentation"
+}
+
+func (whitespaceCheck) HookTypes() []string { return []string{"pre-commit"} }
+
+func (whitespaceCheck) ValidateOptions(opts Options) error {
+	_, err := newWhitespacePolicy(opts)
+	return err
+}
+
+func (whitespaceCheck) Run(ctx *Context) ([]Finding, error) {
+	policy, err := newWhitespacePolicy(ctx.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Problems are looked for in what is staged, as that is what gets
+	// committed, but fixed in the working tree
+	blobs, err := stagedBlobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var checked []blob
+	var paths, shas []string
+	for _, b := range blobs {
+		if b.Mode != "120000" && !matchAny(policy.exclude, b.Path) {
+			checked = append(checked, b)
+			paths = append(paths, b.Path)
+			shas = append(shas, b.SHA)
+		}
+	}
+	filters, err := gitAttribute(ctx, "filter", paths)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := blobContents(ctx, shas)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, b := range checked {
+		content, ok := contents[b.SHA]
+		if !ok || filters[b.Path] == "lfs" || isBinary(content) {
+			continue
+		}
+		style := policy.style(b.Path)
+		_, problems := style.apply(content)
+		if len(problems) == 0 {
+			continue
+		}
+		if policy.fix {
+			fixed, err := fixWorktreeFile(ctx, b.Path, style)
+			if err != nil {
+				return nil, err
+			}
+			// The fix is for the runner to stage or the user to review;
+			// when the working tree has nothing left to fix the staged
+			// copy is still wrong
+			if fixed {
+				for i := range problems {
+					problems[i].Severity = SeverityWarning
+					problems[i].Message = "fixed: " + problems[i].Message
+				}
+			}
+		}
+		for _, problem := range problems {
+			problem.File = b.Path
+			findings = append(findings, problem)
+		}
+	}
+	return findings, nil
+}
+
+// fixWorktreeFile applies style to the working tree copy of file, reporting
+// whether it changed.
+func fixWorktreeFile(ctx *Context, file string, style whitespaceStyle) (bool, error) {
+	path := filepath.Join(ctx.RepoRoot, filepath.FromSlash(file))
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) || err == nil && !info.Mode().IsRegular() {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	if isBinary(content) {
+		return false, nil
+	}
+	fixed, problems := style.apply(content)
+	if len(problems) == 0 {
+		return false, nil
+	}
+	if err := os.WriteFile(path, fixed, info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("failed to fix %s: %w", file, err)
+	}
+	return true, nil
+}
+
+func newWhitespacePolicy(opts Options) (*whitespacePolicy, error) {
+	options := whitespaceOptions{Mode: "check", Exclude: defaultWhitespaceExclude}
+	if err := opts.Decode(&options); err != nil {
+		return nil, err
+	}
+	if options.Mode != "check" && options.Mode != "fix" {
+		return nil, fmt.Errorf("invalid mode '%s', expected check or fix", options.Mode)
+	}
+	policy := &whitespacePolicy{fix: options.Mode == "fix"}
+
+	defaults := whitespaceStyle{trailingWhitespace: true, finalNewline: true, lineEndings: "consistent", indent: "none", tabWidth: 4}
+	base, err := defaults.with(options.whitespaceSettings)
+	if err != nil {
+		return nil, err
+	}
+	policy.base = base
+	// The default rules come first, so that rules of the options can
+	// override them
+	for _, rule := range defaultWhitespaceRules {
+		compiled, _ := compileWhitespaceRule("rules", rule, base)
+		policy.rules = append(policy.rules, compiled)
+	}
+	for i, rule := range options.Rules {
+		compiled, err := compileWhitespaceRule(fmt.Sprintf("rules[%d]", i), rule, base)
+		if err != nil {
+			return nil, err
+		}
+		policy.rules = append(policy.rules, compiled)
+	}
+	exclude, err := compileGlobs("exclude", options.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	policy.exclude = exclude
+	return policy, nil
+}
+
+// compileWhitespaceRule compiles the globs of a rule and checks its
+// settings, naming the option it came from in errors.
+func compileWhitespaceRule(option string, rule whitespaceRule, base whitespaceStyle) (compiledWhitespaceRule, error) {
+	files, err := compileGlobs(option+".files", rule.Files)
+	if err != nil {
+		return compiledWhitespaceRule{}, err
+	}
+	if _, err := base.with(rule.whitespaceSettings); err != nil {
+		return compiledWhitespaceRule{}, fmt.Errorf("%s: %w", option, err)
+	}
+	return compiledWhitespaceRule{files: files, settings: rule.whitespaceSettings}, nil
+}
+
+// style returns the whitespace file must have.
+func (p *whitespacePolicy) style(file string) whitespaceStyle {
+	style := p.base
+	for _, rule := range p.rules {
+		if matchAny(rule.files, file) {
+			// Settings were validated with the policy
+			style, _ = style.with(rule.settings)
+		}
+	}
+	return style
+}
+
+// with returns s with the fields settings set applied on top.
+func (s whitespaceStyle) with(settings whitespaceSettings) (whitespaceStyle, error) {
+	if settings.TrailingWhitespace != nil {
+		s.trailingWhitespace = *settings.TrailingWhitespace
+	}
+	if settings.FinalNewline != nil {
+		s.finalNewline = *settings.FinalNewline
+	}
+	switch settings.LineEndings {
+	case "":
+	case "lf", "crlf", "consistent", "none":
+		s.lineEndings = settings.LineEndings
+	default:
+		return s, fmt.Errorf("invalid lineEndings '%s', expected lf, crlf, consistent or none", settings.LineEndings)
+	}
+	switch settings.Indent {
+	case "":
+	case "tabs", "spaces", "none":
+		s.indent = settings.Indent
+	default:
+		return s, fmt.Errorf("invalid indent '%s', expected tabs, spaces or none", settings.Indent)
+	}
+	if settings.TabWidth < 0 {
+		return s, fmt.Errorf("invalid tabWidth %d", settings.TabWidth)
+	}
+	if settings.TabWidth > 0 {
+		s.tabWidth = settings.TabWidth
+	}
+	return s, nil
+}
+
+// apply returns content with its whitespace corrected, and the problems
+// corrected.
+func (s whitespaceStyle) apply(content []byte) ([]byte, []Finding) {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	crlf, lf := 0, 0
+	for _, line := range lines {
+		if strings.HasSuffix(line, "\r\n") {
+			crlf++
+		} else if strings.HasSuffix(line, "\n") {
+			lf++
+		}
+	}
+	// Files with mixed line endings are made consistent with the ending
+	// most of their lines have
+	ending := ""
+	switch {
+	case s.lineEndings == "lf":
+		ending = "\n"
+	case s.lineEndings == "crlf":
+		ending = "\r\n"
+	case s.lineEndings == "consistent" && crlf > 0 && lf > 0:
+		ending = "\n"
+		if crlf > lf {
+			ending = "\r\n"
+		}
+	}
+	newline := ending
+	if newline == "" {
+		newline = "\n"
+		if crlf > lf {
+			newline = "\r\n"
+		}
+	}
+
+	var findings []Finding
+	counts := make(map[string]int)
+	report := func(line, column int, rule, format string, args ...any) {
+		if counts[rule]++; counts[rule] <= maxWhitespaceFindings {
+			findings = append(findings, Finding{Line: line, Column: column, Rule: rule, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	var fixed strings.Builder
+	wrongEndings, firstWrongEnding := 0, 0
+	for i, line := range lines {
+		number := i + 1
+		body, eol := line, ""
+		if strings.HasSuffix(body, "\n") {
+			body, eol = strings.TrimSuffix(body, "\n"), "\n"
+			if strings.HasSuffix(body, "\r") {
+				body, eol = strings.TrimSuffix(body, "\r"), "\r\n"
+			}
+		}
+		if eol != "" && ending != "" && eol != ending {
+			if wrongEndings++; firstWrongEnding == 0 {
+				firstWrongEnding = number
+			}
+			eol = ending
+		}
+		if s.indent == "tabs" || s.indent == "spaces" {
+			body = s.fixIndent(body, number, report)
+		}
+		if s.trailingWhitespace {
+			if trimmed := strings.TrimRight(body, " \t"); trimmed != body {
+				report(number, utf8.RuneCountInString(trimmed)+1, "trailing-whitespace", "trailing whitespace")
+				body = trimmed
+			}
+		}
+		if eol == "" && body != "" && s.finalNewline {
+			report(number, utf8.RuneCountInString(body)+1, "final-newline", "missing newline at the end of the file")
+			eol = newline
+		}
+		fixed.WriteString(body + eol)
+	}
+
+	if wrongEndings > 0 {
+		found, expected := "CRLF", "LF"
+		if ending == "\r\n" {
+			found, expected = "LF", "CRLF"
+		}
+		message := fmt.Sprintf("%d line(s) end in %s, %s expected", wrongEndings, found, expected)
+		if s.lineEndings == "consistent" {
+			message = "mixed line endings: " + message
+		}
+		findings = append(findings, Finding{Line: firstWrongEnding, Rule: "line-endings", Message: message})
+	}
+	rules := make([]string, 0, len(counts))
+	for rule := range counts {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		if n := counts[rule] - maxWhitespaceFindings; n > 0 {
+			findings = append(findings, Finding{Rule: rule, Message: fmt.Sprintf("%d more line(s) like this", n)})
+		}
+	}
+	return []byte(fixed.String()), findings
+}
+
+// fixIndent converts the indentation of a line to tabs or spaces.
+func (s whitespaceStyle) fixIndent(body string, number int, report func(int, int, string, string, ...any)) string {
+	rest := strings.TrimLeft(body, " \t")
+	indent := body[:len(body)-len(rest)]
+	switch {
+	case s.indent == "spaces" && strings.Contains(indent, "\t"):
+		report(number, strings.IndexByte(indent, '\t')+1, "indent", "indentation with tabs, spaces expected")
+	// Spaces after tabs align rather than indent, unless there are enough
+	// of them for a tab
+	case s.indent == "tabs" && (strings.Contains(indent, strings.Repeat(" ", s.tabWidth)) || strings.Contains(indent, " \t")):
+		report(number, strings.IndexByte(indent, ' ')+1, "indent", "indentation with spaces, tabs expected")
+	default:
+		return body
+	}
+	width := 0
+	for _, c := range indent {
+		if c == '\t' {
+			width += s.tabWidth - width%s.tabWidth
+		} else {
+			width++
+		}
+	}
+	if s.indent == "spaces" {
+		return strings.Repeat(" ", width) + rest
+	}
+	return strings.Repeat("\t", width/s.tabWidth) + strings.Repeat(" ", width%s.tabWidth) + rest
+}
@@ -0,0 +1,116 @@
+package checks
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWhitespaceStyleApply(t *testing.T) {
+	defaults := whitespaceStyle{trailingWhitespace: true, finalNewline: true, lineEndings: "consistent", indent: "none", tabWidth: 4}
+	with := func(change func(*whitespaceStyle)) whitespaceStyle {
+		style := defaults
+		change(&style)
+		return style
+	}
+	tests := []struct {
+		name    string
+		style   whitespaceStyle
+		content string
+		fixed   string
+		// want lists the rules reported, as "rule@line:column".
+		want []string
+	}{
+		{name: "clean", style: defaults, content: "a\n\tb\n", fixed: "a\n\tb\n"},
+		{name: "empty", style: defaults, content: "", fixed: ""},
+		{name: "trailing whitespace", style: defaults, content: "a \nb\t\n", fixed: "a\nb\n", want: []string{"trailing-whitespace@1:2", "trailing-whitespace@2:2"}},
+		{name: "trailing whitespace allowed", style: with(func(s *whitespaceStyle) { s.trailingWhitespace = false }), content: "a  \n", fixed: "a  \n"},
+		{name: "final newline", style: defaults, content: "a\nb", fixed: "a\nb\n", want: []string{"final-newline@2:2"}},
+		{name: "final newline keeps CRLF", style: defaults, content: "a\r\nb", fixed: "a\r\nb\r\n", want: []string{"final-newline@2:2"}},
+		{name: "final newline not required", style: with(func(s *whitespaceStyle) { s.finalNewline = false }), content: "a", fixed: "a"},
+		{name: "mixed endings follow the majority", style: defaults, content: "a\r\nb\r\nc\n", fixed: "a\r\nb\r\nc\r\n", want: []string{"line-endings@3:0"}},
+		{name: "consistent CRLF", style: defaults, content: "a\r\nb\r\n", fixed: "a\r\nb\r\n"},
+		{name: "LF required", style: with(func(s *whitespaceStyle) { s.lineEndings = "lf" }), content: "a\r\nb\r\n", fixed: "a\nb\n", want: []string{"line-endings@1:0"}},
+		{name: "CRLF required", style: with(func(s *whitespaceStyle) { s.lineEndings = "crlf" }), content: "a\nb\r\n", fixed: "a\r\nb\r\n", want: []string{"line-endings@1:0"}},
+		{name: "line endings not checked", style: with(func(s *whitespaceStyle) { s.lineEndings = "none" }), content: "a\r\nb\n", fixed: "a\r\nb\n"},
+		{name: "spaces required", style: with(func(s *whitespaceStyle) { s.indent = "spaces" }), content: "\tx\n  \ty\n", fixed: "    x\n    y\n", want: []string{"indent@1:1", "indent@2:3"}},
+		{name: "tabs required", style: with(func(s *whitespaceStyle) { s.indent = "tabs" }), content: "        x\n\t  y\n", fixed: "\t\tx\n\t  y\n", want: []string{"indent@1:1"}},
+		{name: "tab width", style: with(func(s *whitespaceStyle) { s.indent = "spaces"; s.tabWidth = 2 }), content: "\t\tx\n", fixed: "    x\n", want: []string{"indent@1:1"}},
+		{name: "findings are capped", style: defaults, content: strings.Repeat("x \n", 12), fixed: strings.Repeat("x\n", 12), want: []string{
+			"trailing-whitespace@1:2", "trailing-whitespace@2:2", "trailing-whitespace@3:2", "trailing-whitespace@4:2", "trailing-whitespace@5:2",
+			"trailing-whitespace@6:2", "trailing-whitespace@7:2", "trailing-whitespace@8:2", "trailing-whitespace@9:2", "trailing-whitespace@10:2",
+			"trailing-whitespace@0:0",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixed, findings := tt.style.apply([]byte(tt.content))
+			if string(fixed) != tt.fixed {
+				t.Errorf("apply(%q) fixed = %q, want %q", tt.content, fixed, tt.fixed)
+			}
+			var got []string
+			for _, finding := range findings {
+				got = append(got, fmt.Sprintf("%s@%d:%d", finding.Rule, finding.Line, finding.Column))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("apply(%q) = %v, want %v", tt.content, got, tt.want)
+			}
+			// Fixing is idempotent
+			if _, again := tt.style.apply(fixed); len(again) > 0 {
+				t.Errorf("apply() of the fixed content reported %v", again)
+			}
+		})
+	}
+}
+
+func TestWhitespacePolicyStyle(t *testing.T) {
+	policy, err := newWhitespacePolicy(Options{
+		"indent": "spaces",
+		"rules": []any{
+			map[string]any{"files": []any{"Makefile", "*.mk"}, "indent": "tabs"},
+			map[string]any{"files": []any{"docs/**"}, "trailingWhitespace": true, "lineEndings": "crlf"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		file string
+		want whitespaceStyle
+	}{
+		{"main.go", whitespaceStyle{trailingWhitespace: true, finalNewline: true, lineEndings: "consistent", indent: "spaces", tabWidth: 4}},
+		{"sub/Makefile", whitespaceStyle{trailingWhitespace: true, finalNewline: true, lineEndings: "consistent", indent: "tabs", tabWidth: 4}},
+		{"README.md", whitespaceStyle{trailingWhitespace: false, finalNewline: true, lineEndings: "consistent", indent: "spaces", tabWidth: 4}},
+		// Rules of the options come after the default Markdown one
+		{"docs/guide.md", whitespaceStyle{trailingWhitespace: true, finalNewline: true, lineEndings: "crlf", indent: "spaces", tabWidth: 4}},
+	}
+	for _, tt := range tests {
+		if got := policy.style(tt.file); got != tt.want {
+			t.Errorf("style(%q) = %+v, want %+v", tt.file, got, tt.want)
+		}
+	}
+	if !matchAny(policy.exclude, "fixes/0001.patch") {
+		t.Errorf("patches are not excluded by default")
+	}
+}
+
+func TestNewWhitespacePolicyErrors(t *testing.T) {
+	tests := []struct {
+		opts    Options
+		wantErr string
+	}{
+		{Options{"mode": "repair"}, "invalid mode"},
+		{Options{"lineEndings": "cr"}, "invalid lineEndings"},
+		{Options{"indent": "both"}, "invalid indent"},
+		{Options{"tabWidth": -1}, "invalid tabWidth"},
+		{Options{"rules": []any{map[string]any{"files": []any{"*.go"}, "indent": "both"}}}, "rules[0]: invalid indent"},
+		{Options{"rules": []any{map[string]any{"files": []any{"[x"}}}}, "rules[0].files"},
+		{Options{"rules": []any{map[string]any{"file": "*.go"}}}, "unknown field 'file'"},
+	}
+	for _, tt := range tests {
+		if _, err := newWhitespacePolicy(tt.opts); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("newWhitespacePolicy(%v) error = %v, want one containing %q", tt.opts, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hookAsset is a supporting file shipped with a hook, such as a config file or
+// a library its script sources.
+type hookAsset struct {
+	Path string // relative to the hook's directory, slash separated
+	Data []byte
+	Mode fs.FileMode
+}
+
+// hookStoreDir returns the per-hook directory holding a hook's assets. Hooks
+// find it through the OMNIHOOK_HOOK_DIR environment variable.
+func hookStoreDir(hooksDir, id string) string {
+	return filepath.Join(hooksDir, ".store", filepath.FromSlash(id))
+}
+
+// storeHookFile is the name of the installed hook executable inside its
+// per-hook directory; the hook type directories link to it.
+const storeHookFile = ".hook"
+
+// hookStoreFile returns the location of a hook's executable.
+func hookStoreFile(hooksDir, id string) string {
+	return filepath.Join(hookStoreDir(hooksDir, id), storeHookFile)
+}
+
+// loadAssets reads the listed files and directories relative to baseDir.
+// Assets must stay inside baseDir so a hook source cannot pull in arbitrary
+// files from the machine it is installed on.
+func loadAssets(baseDir string, paths []string) ([]hookAsset, error) {
+	var assets []hookAsset
+	for _, assetPath := range paths {
+		if filepath.IsAbs(assetPath) {
+			return nil, fmt.Errorf("asset %s must be a relative path", assetPath)
+		}
+		fullPath := filepath.Join(baseDir, assetPath)
+		if !strings.HasPrefix(fullPath, baseDir+string(os.PathSeparator)) {
+			return nil, fmt.Errorf("asset %s is outside of %s", assetPath, baseDir)
+		}
+
+		err := filepath.WalkDir(fullPath, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil // symlinks and other special files are skipped
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(baseDir, path)
+			if err != nil {
+				return err
+			}
+			if filepath.ToSlash(rel) == storeHookFile {
+				return fmt.Errorf("%s is reserved for the hook executable", storeHookFile)
+			}
+			assets = append(assets, hookAsset{Path: filepath.ToSlash(rel), Data: data, Mode: info.Mode().Perm()})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read asset %s: %w", assetPath, err)
+		}
+	}
+	return assets, nil
+}
+
+// writeHookStore replaces a hook's directory with one holding its executable
+// and assets. Everything is written to a temporary directory next to it
+// first, so a failed install leaves the previous one in place.
+func writeHookStore(storeDir string, content []byte, assets []hookAsset) error {
+	parent := filepath.Dir(storeDir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return err
+	}
+	tempDir, err := os.MkdirTemp(parent, "."+filepath.Base(storeDir)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	if err := os.Chmod(tempDir, 0755); err != nil {
+		return err
+	}
+
+	for _, asset := range assets {
+		target := filepath.Join(tempDir, filepath.FromSlash(asset.Path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, asset.Data, asset.Mode|0400); err != nil {
+			return err
+		}
+	}
+	hookFile := filepath.Join(tempDir, storeHookFile)
+	if err := os.WriteFile(hookFile, content, 0755); err != nil {
+		return fmt.Errorf("failed to write hook file: %w", err)
+	}
+	// Explicitly set executable permissions, which the umask may have dropped
+	if err := os.Chmod(hookFile, 0755); err != nil {
+		return fmt.Errorf("failed to set executable permissions: %w", err)
+	}
+
+	// A directory cannot be renamed over another, so the previous install
+	// is moved aside and only deleted once the new one is in place
+	previous := tempDir + ".old"
+	if err := os.Rename(storeDir, previous); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Rename(tempDir, storeDir); err != nil {
+		os.Rename(previous, storeDir)
+		return err
+	}
+	return os.RemoveAll(previous)
+}
+
+// removeHookStore deletes a hook's directory, along with its alias directory
+// once that is empty.
+func removeHookStore(hooksDir, id string) {
+	storeDir := hookStoreDir(hooksDir, id)
+	os.RemoveAll(storeDir)
+	if strings.Contains(id, "/") {
+		os.Remove(filepath.Dir(storeDir))
+	}
+}
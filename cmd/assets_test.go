@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAssetsRejectsHookFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, storeHookFile), []byte("echo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadAssets(dir, []string{storeHookFile}); err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Errorf("loadAssets() error = %v, want the hook file rejected", err)
+	}
+}
+
+func TestWriteHookStore(t *testing.T) {
+	storeDir := filepath.Join(t.TempDir(), ".store", "alias", "lint")
+	first := []hookAsset{{Path: "lib/common.sh", Data: []byte("v1"), Mode: 0644}, {Path: "old.txt", Data: []byte("old"), Mode: 0644}}
+	if err := writeHookStore(storeDir, []byte("#!/bin/sh\necho v1\n"), first); err != nil {
+		t.Fatalf("writeHookStore() error = %v", err)
+	}
+
+	// A failed install keeps the previous one
+	broken := []hookAsset{{Path: "lib", Data: []byte("file"), Mode: 0644}, {Path: "lib/common.sh", Data: []byte("v2"), Mode: 0644}}
+	if err := writeHookStore(storeDir, []byte("#!/bin/sh\necho v2\n"), broken); err == nil {
+		t.Fatal("writeHookStore() with conflicting assets succeeded")
+	}
+	assertFile(t, filepath.Join(storeDir, storeHookFile), "#!/bin/sh\necho v1\n")
+	assertFile(t, filepath.Join(storeDir, "lib", "common.sh"), "v1")
+
+	// A successful one replaces it entirely
+	second := []hookAsset{{Path: "lib/common.sh", Data: []byte("v2"), Mode: 0644}}
+	if err := writeHookStore(storeDir, []byte("#!/bin/sh\necho v2\n"), second); err != nil {
+		t.Fatalf("writeHookStore() error = %v", err)
+	}
+	assertFile(t, filepath.Join(storeDir, storeHookFile), "#!/bin/sh\necho v2\n")
+	assertFile(t, filepath.Join(storeDir, "lib", "common.sh"), "v2")
+	if _, err := os.Stat(filepath.Join(storeDir, "old.txt")); !os.IsNotExist(err) {
+		t.Errorf("asset of the previous install was kept")
+	}
+	if info, err := os.Stat(filepath.Join(storeDir, storeHookFile)); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("hook file is not executable: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(storeDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary directories were left behind: %v", entries)
+	}
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	if string(data) != want {
+		t.Errorf("%s = %q, want %q", path, data, want)
+	}
+}
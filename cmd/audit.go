@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Audit actions
+const (
+	auditSkipped = "skipped"
+	auditRefused = "refused"
+)
+
+// auditEntry records a request to skip a hook, whether it was honored or
+// refused because the hook is required.
+type auditEntry struct {
+	Time     time.Time `json:"time"`
+	Action   string    `json:"action"`
+	User     string    `json:"user"`
+	Repo     string    `json:"repo,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+	Commit   string    `json:"commit,omitempty"`
+	HookType string    `json:"hookType"`
+	Hook     string    `json:"hook"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show or export the log of skipped hooks",
+	Long: `Show the audit log of hooks skipped with OMNIHOOK_SKIP or run --skip, and of
+skips refused because the hook is required. Use --format to export it as
+JSON lines or CSV.`,
+	RunE: showAuditLog,
+}
+
+func init() {
+	auditCmd.Flags().String("format", "table", "Output format: table, jsonl or csv")
+	auditCmd.Flags().String("output", "", "Write to a file instead of stdout")
+	auditCmd.Flags().Duration("since", 0, "Only include entries newer than this, e.g. 168h")
+	rootCmd.AddCommand(auditCmd)
+}
+
+// getAuditLogPath keeps the audit log next to the cache, so a separate
+// install such as omnihook ci's has a log of its own.
+func getAuditLogPath() string {
+	return filepath.Join(filepath.Dir(getCacheFilePath()), "audit.jsonl")
+}
+
+// appendAuditEntries adds entries to the audit log. The log is only ever
+// appended to.
+func appendAuditEntries(entries []auditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	path := getAuditLogPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+	}
+	return nil
+}
+
+func readAuditLog() ([]auditEntry, error) {
+	file, err := os.Open(getAuditLogPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []auditEntry
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("audit log line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// auditUser identifies who skipped a hook by their git identity, falling
+// back to the login name.
+func auditUser() string {
+	name, _ := exec.Command("git", "config", "user.name").Output()
+	email, _ := exec.Command("git", "config", "user.email").Output()
+	identity := strings.TrimSpace(string(name))
+	if e := strings.TrimSpace(string(email)); e != "" {
+		identity = strings.TrimSpace(identity + " <" + e + ">")
+	}
+	if identity != "" {
+		return identity
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return "unknown"
+}
+
+func showAuditLog(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	since, _ := cmd.Flags().GetDuration("since")
+	if format != "table" && format != "jsonl" && format != "csv" {
+		return fmt.Errorf("invalid format '%s' (expected table, jsonl or csv)", format)
+	}
+
+	entries, err := readAuditLog()
+	if err != nil {
+		return err
+	}
+	if since > 0 {
+		cutoff := time.Now().Add(-since)
+		var recent []auditEntry
+		for _, entry := range entries {
+			if entry.Time.After(cutoff) {
+				recent = append(recent, entry)
+			}
+		}
+		entries = recent
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch format {
+	case "jsonl":
+		encoder := json.NewEncoder(w)
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+		}
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"time", "action", "user", "repo", "branch", "commit", "hookType", "hook", "reason"})
+		for _, entry := range entries {
+			writer.Write([]string{entry.Time.Format(time.RFC3339), entry.Action, entry.User, entry.Repo, entry.Branch, entry.Commit, entry.HookType, entry.Hook, entry.Reason})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		if len(entries) == 0 {
+			fmt.Fprintln(w, "No hooks have been skipped.")
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TIME\tACTION\tHOOK\tTYPE\tUSER\tREPO\tCOMMIT\tREASON")
+		for _, entry := range entries {
+			commit := entry.Commit
+			if len(commit) > 12 {
+				commit = commit[:12]
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.Time.Local().Format("2006-01-02 15:04"), entry.Action, entry.Hook, entry.HookType,
+				entry.User, valueOrDash(entry.Repo), valueOrDash(commit), valueOrDash(entry.Reason))
+		}
+		tw.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vjayajv/omnihook/checks"
+	"github.com/vjayajv/omnihook/utils"
+)
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline <[alias/]hook_id>",
+	Short: "Record the problems a built-in check finds today so only new ones fail",
+	Long: `Run a built-in check over every file tracked in the current repository and
+record what it finds in a baseline file, so that problems a legacy repository
+already has do not block every commit while new ones still do. Commit the
+baseline file to share it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: writeBaseline,
+}
+
+func init() {
+	rootCmd.AddCommand(baselineCmd)
+}
+
+func writeBaseline(cmd *cobra.Command, args []string) error {
+	hooksDir := viper.GetString("omni_hooks_dir")
+	if hooksDir == "" {
+		return errors.New("hooks directory not set. Run 'omnihook configure' first")
+	}
+	repoRoot, err := utils.GitRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	targets, err := hookTargets(hooksDir, "", args[0])
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("hook '%s' does not exist", args[0])
+	}
+	cache, err := readCache()
+	if err != nil {
+		return err
+	}
+	record, _ := cache.findHook(targets[0].HookType, targets[0].ID)
+	if record.Builtin == "" {
+		return fmt.Errorf("hook '%s' is not a built-in check", targets[0].ID)
+	}
+	check, ok := checks.Lookup(record.Builtin)
+	if !ok {
+		return fmt.Errorf("unknown builtin '%s', omnihook may need upgrading", record.Builtin)
+	}
+	baseliner, ok := check.(checks.Baseliner)
+	if !ok {
+		return fmt.Errorf("builtin '%s' does not support baselines", record.Builtin)
+	}
+
+	layers, err := loadOverrideLayers(repoRoot)
+	if err != nil {
+		return err
+	}
+	files, err := utils.GitTrackedFiles()
+	if err != nil {
+		return err
+	}
+	path, count, err := baseliner.WriteBaseline(&checks.Context{
+		HookType: targets[0].HookType,
+		RepoRoot: repoRoot,
+		Files:    files,
+		Options:  layers.apply(targets[0].ID, record.HookSettings).Options,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded %d finding(s) in %s\n", count, path)
+	return nil
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/vjayajv/omnihook/checks"
+)
+
+var builtinsCmd = &cobra.Command{
+	Use:   "builtins",
+	Short: "List the checks built into omnihook",
+	Long: `List the checks built into omnihook. A hook runs one with "builtin: <name>"
+instead of a script, configured through its options.`,
+	RunE: listBuiltins,
+}
+
+func init() {
+	rootCmd.AddCommand(builtinsCmd)
+}
+
+func listBuiltins(cmd *cobra.Command, args []string) error {
+	all := checks.All()
+	if len(all) == 0 {
+		fmt.Println("No built-in checks available.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tHOOK TYPES\tDESCRIPTION")
+	for _, check := range all {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name(), strings.Join(check.HookTypes(), ", "), check.Description())
+	}
+	return w.Flush()
+}
+
+// builtinNames lists the registered checks, for the schema.
+func builtinNames() []string {
+	var names []string
+	for _, check := range checks.All() {
+		names = append(names, check.Name())
+	}
+	return names
+}
+
+// validateBuiltin checks that a builtin hook names a known check, only runs
+// it as hook types it supports, and gives it options it accepts.
+func validateBuiltin(hook Hook) error {
+	if hook.Script != "" || hook.ScriptPath != "" || hook.Entry != "" {
+		return errors.New("builtin hooks cannot have a script, scriptPath or entry")
+	}
+	if hook.Language != "" {
+		return errors.New("builtin hooks cannot set a language")
+	}
+	check, ok := checks.Lookup(hook.Builtin)
+	if !ok {
+		return fmt.Errorf("unknown builtin '%s', run 'omnihook builtins' to list them", hook.Builtin)
+	}
+	for _, hookType := range hookTypesOf(hook) {
+		if !checks.Supports(check, hookType) {
+			return fmt.Errorf("builtin '%s' cannot run as %s, only as: %s", hook.Builtin, hookType, strings.Join(check.HookTypes(), ", "))
+		}
+	}
+	if validator, ok := check.(checks.OptionsValidator); ok {
+		if err := validator.ValidateOptions(hook.Options); err != nil {
+			return fmt.Errorf("builtin '%s': %w", hook.Builtin, err)
+		}
+	}
+	return nil
+}
+
+// builtinStub is what gets installed for a builtin hook. The check runs
+// inside omnihook, so the file only explains that to anyone who runs it
+// directly.
+func builtinStub(hook Hook) string {
+	return fmt.Sprintf("#!/bin/sh\n# %s\necho \"%s is built into omnihook, run it with: omnihook run --hook %s\" >&2\nexit 1\n",
+		hook.Description, hook.Builtin, hook.ID)
+}
+
+// runBuiltin runs a check in-process and renders its findings as a script
+// would print them. The hook passes unless there is an error finding or the
+// check could not run.
+func runBuiltin(name string, ctx *checks.Context) ([]checks.Finding, string, error) {
+	check, ok := checks.Lookup(name)
+	if !ok {
+		return nil, "", fmt.Errorf("unknown builtin '%s', omnihook may need upgrading", name)
+	}
+	findings, err := check.Run(ctx)
+	var output strings.Builder
+	for _, finding := range findings {
+		output.WriteString(finding.String())
+		output.WriteByte('\n')
+	}
+	if err != nil {
+		fmt.Fprintf(&output, "%s: %v\n", name, err)
+		return findings, output.String(), err
+	}
+	for _, finding := range findings {
+		if finding.IsError() {
+			return findings, output.String(), errors.New("check failed")
+		}
+	}
+	return findings, output.String(), nil
+}
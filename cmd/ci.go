@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vjayajv/omnihook/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// repoLockFile pins the hook sources of a repository, as written by
+// omnihook lock. It sits next to the repo config at the repository root.
+const repoLockFile = ".omnihook.lock"
+
+var ciCmd = &cobra.Command{
+	Use:   "ci [--from-ref <ref> [--to-ref <ref>] | --all-files | --files <file>...]",
+	Short: "Install and run the repository's hooks in a CI pipeline",
+	Long: `Install the hook sources pinned by the repository into a temporary directory
+and run them, without touching the user's omnihook setup or git config.
+
+Sources are read from --lockfile, else from .omnihook.lock at the repository
+root, else from the sources listed in its .omnihook.yml. Hooks run against
+the files changed in --from-ref...--to-ref, or every tracked file when no
+range is given. Results can be written as JUnit XML and SARIF for the
+pipeline to pick up, and the exit code is non-zero if any hook failed.`,
+	RunE: runCI,
+}
+
+func init() {
+	ciCmd.Flags().String("lockfile", "", "Lockfile to install hook sources from (default: .omnihook.lock, then the sources in .omnihook.yml)")
+	ciCmd.Flags().StringSlice("type", []string{"pre-commit"}, "Hook types to run (repeatable)")
+	ciCmd.Flags().String("hook", "", "Run a single hook")
+	ciCmd.Flags().StringSlice("files", nil, "Run against these files; further arguments are taken as files too")
+	ciCmd.Flags().Bool("all-files", false, "Run against every file tracked in the repository (the default without --from-ref)")
+	ciCmd.Flags().String("from-ref", "", "Run against the files changed since this ref, such as the pull request's base")
+	ciCmd.Flags().String("to-ref", "HEAD", "End of the range started by --from-ref")
+	ciCmd.Flags().String("junit", "", "Write a JUnit XML report to this file")
+	ciCmd.Flags().String("sarif", "", "Write a SARIF report to this file")
+	ciCmd.MarkFlagsMutuallyExclusive("files", "all-files", "from-ref")
+	rootCmd.AddCommand(ciCmd)
+}
+
+// sourceSpec is a hook source as listed under sources in the repo config or
+// a lockfile. Exactly one of URL, File and Dir is set.
+type sourceSpec struct {
+	URL    string `yaml:"url,omitempty"`
+	File   string `yaml:"file,omitempty"`
+	Dir    string `yaml:"dir,omitempty"`
+	Alias  string `yaml:"alias,omitempty"`
+	Ref    string `yaml:"ref,omitempty"`
+	SHA256 string `yaml:"sha256,omitempty"`
+}
+
+// sourceList is the shape shared by lockfiles and the sources section of the
+// repo config.
+type sourceList struct {
+	Sources []sourceSpec `yaml:"sources"`
+}
+
+// installOptions resolves the spec into options for installHook. Relative
+// file and directory paths are taken from baseDir.
+func (s sourceSpec) installOptions(baseDir string) (installOptions, error) {
+	set := 0
+	for _, value := range []string{s.URL, s.File, s.Dir} {
+		if value != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return installOptions{}, errors.New("each source must set exactly one of url, file or dir")
+	}
+
+	resolve := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(baseDir, path)
+	}
+	return installOptions{
+		URL:      s.URL,
+		File:     resolve(s.File),
+		Dir:      resolve(s.Dir),
+		Checksum: s.SHA256,
+		Alias:    s.Alias,
+		Ref:      s.Ref,
+		Quiet:    true,
+	}, nil
+}
+
+func readSourceList(path string) (sourceList, error) {
+	var list sourceList
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return list, err
+	}
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return list, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return list, nil
+}
+
+// ciSources finds the hook sources CI installs: an explicit lockfile, the
+// repository's lockfile, or the sources in its repo config.
+func ciSources(lockfile, repoRoot string) ([]sourceSpec, string, error) {
+	if lockfile != "" {
+		list, err := readSourceList(lockfile)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read lockfile: %w", err)
+		}
+		return list.Sources, lockfile, nil
+	}
+
+	for _, name := range []string{repoLockFile, repoConfigFile} {
+		path := filepath.Join(repoRoot, name)
+		list, err := readSourceList(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if len(list.Sources) > 0 {
+			return list.Sources, path, nil
+		}
+	}
+	return nil, "", fmt.Errorf("no hook sources found: add sources to %s or run 'omnihook lock'", repoConfigFile)
+}
+
+func runCI(cmd *cobra.Command, args []string) error {
+	lockfile, _ := cmd.Flags().GetString("lockfile")
+	hookTypes, _ := cmd.Flags().GetStringSlice("type")
+	hookID, _ := cmd.Flags().GetString("hook")
+	junitFile, _ := cmd.Flags().GetString("junit")
+	sarifFile, _ := cmd.Flags().GetString("sarif")
+	if hookID != "" && !cmd.Flags().Changed("type") {
+		hookTypes = nil
+	}
+	for _, t := range hookTypes {
+		if !isValidHookType(t) {
+			return fmt.Errorf("invalid hook type '%s'", t)
+		}
+	}
+
+	repoRoot, err := utils.GitRepoRoot()
+	if err != nil {
+		return err
+	}
+	sources, sourcesFile, err := ciSources(lockfile, repoRoot)
+	if err != nil {
+		return err
+	}
+	files, explicitFiles, err := runFiles(cmd, args, repoRoot)
+	if err != nil {
+		return err
+	}
+	if !explicitFiles {
+		// Nothing is staged in CI, so the default is the whole repository
+		if files, err = utils.GitTrackedFiles(); err != nil {
+			return err
+		}
+	}
+
+	// Hooks are installed into a throwaway directory with a cache of their
+	// own, leaving any omnihook setup on the machine untouched.
+	tempDir, err := os.MkdirTemp("", "omnihook-ci-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+	hooksDir := filepath.Join(tempDir, "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	viper.Set("omni_hooks_dir", hooksDir)
+	viper.Set("omni_cache_file", filepath.Join(tempDir, "cache.yml"))
+
+	fmt.Printf("Installing hooks from %s\n", sourcesFile)
+	for i, source := range sources {
+		opts, err := source.installOptions(repoRoot)
+		if err != nil {
+			return fmt.Errorf("source %d in %s: %w", i+1, sourcesFile, err)
+		}
+		if err := installHook(opts); err != nil {
+			return fmt.Errorf("failed to install %s: %w", opts.source(), err)
+		}
+		fmt.Printf("  %s\n", opts.source())
+	}
+
+	hooks, err := selectHooks(hooksDir, hookTypes, hookID)
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		fmt.Println("No hooks to run.")
+		return nil
+	}
+
+	fmt.Printf("Running %d hook(s) against %d file(s)\n", len(hooks), len(files))
+	snapshot := snapshotFiles(repoRoot, files)
+	results, err := executeHooks(runRequest{
+		hooksDir:      hooksDir,
+		hooks:         hooks,
+		repoRoot:      repoRoot,
+		files:         files,
+		explicitFiles: true,
+		quiet:         true,
+	})
+	if err != nil {
+		return err
+	}
+
+	failureCount := 0
+	for _, result := range results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+			failureCount++
+		}
+		fmt.Printf("%s  %s (%s) %s\n", status, result.ID, result.HookType, result.Duration.Round(time.Millisecond))
+		if !result.Passed && strings.TrimSpace(result.Output) != "" {
+			for _, line := range strings.Split(strings.TrimRight(result.Output, "\n"), "\n") {
+				fmt.Printf("      %s\n", line)
+			}
+		}
+	}
+
+	// Files fixed in CI are not committed, so the fix has to be made before
+	// pushing
+	modified := snapshot.modified()
+	if len(modified) > 0 {
+		fmt.Println("FAIL  hooks modified files; run them locally and commit the changes:")
+		for _, file := range modified {
+			fmt.Printf("      %s\n", file)
+		}
+	}
+
+	if junitFile != "" {
+		if err := writeJUnitReport(junitFile, results); err != nil {
+			return err
+		}
+	}
+	if sarifFile != "" {
+		if err := writeSARIFReport(sarifFile, results); err != nil {
+			return err
+		}
+	}
+
+	if failureCount > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d of %d hook(s) failed", failureCount, len(results))
+	}
+	if len(modified) > 0 {
+		cmd.SilenceUsage = true
+		return errors.New("hooks modified files")
+	}
+	fmt.Printf("All %d hook(s) passed\n", len(results))
+	return nil
+}
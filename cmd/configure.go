@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 )
 
 // configureCmd represents the configure command
@@ -34,7 +35,7 @@ func configureOmnihook(reset bool) {
 	configDir := filepath.Join(home, ".omnihook")
 	configFile := filepath.Join(configDir, "config.yaml")
 	hooksDir := filepath.Join(configDir, "hooks")
-	hookTypes := []string{"pre-commit", "prepare-commit-msg", "commit-msg", "pre-push"}
+	hookTypes := wrapperHookTypes(hooksDir)
 	gitHooksDir := filepath.Join(home, ".git_hooks")
 
 	if reset {
@@ -58,7 +59,7 @@ func configureOmnihook(reset bool) {
 	}
 
 	// Ensure hooks sub-directories exist
-	for _, hookType := range hookTypes {
+	for _, hookType := range defaultHookTypes {
 		hookTypeDir := filepath.Join(hooksDir, hookType)
 		if err := os.MkdirAll(hookTypeDir, 0755); err != nil {
 			fmt.Println("Error creating hooks sub-directory:", err)
@@ -102,9 +103,79 @@ func setGitHooksPath(gitHooksDir string) error {
 	return cmd.Run()
 }
 
+// defaultHookTypes are the hook types configure sets up wrappers and
+// directories for.
+var defaultHookTypes = []string{"pre-commit", "prepare-commit-msg", "commit-msg", "pre-push"}
+
+// hookArgCounts are the number of arguments git passes to hook types whose
+// wrappers hand them on.
+var hookArgCounts = map[string]int{
+	"post-checkout": 3,
+	"post-merge":    1,
+}
+
+// wrapperHookTypes returns the hook types that need a wrapper: the default
+// ones and any other type hooks are installed for, or those hooks never run.
+func wrapperHookTypes(hooksDir string) []string {
+	hookTypes := append([]string{}, defaultHookTypes...)
+	types, _ := listHookTypes(hooksDir)
+	for _, hookType := range types {
+		files, _ := listHookFiles(hooksDir, hookType)
+		if len(files) > 0 && !slices.Contains(hookTypes, hookType) {
+			hookTypes = append(hookTypes, hookType)
+		}
+	}
+	return hookTypes
+}
+
+// ensureWrappers writes the missing wrappers for the types hooks are
+// installed for, such as post-checkout, once configure has set up the
+// global hooks directory.
+func ensureWrappers(hooksDir string) error {
+	gitHooksDir, err := globalGitHooksDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(gitHooksDir); err != nil {
+		return nil
+	}
+	for _, hookType := range wrapperHookTypes(hooksDir) {
+		wrapperPath := filepath.Join(gitHooksDir, hookType)
+		if _, err := os.Lstat(wrapperPath); err == nil {
+			continue
+		}
+		if err := createGlobalHook(wrapperPath); err != nil {
+			return fmt.Errorf("failed to create wrapper %s: %w", wrapperPath, err)
+		}
+		fmt.Printf("Created wrapper %s\n", wrapperPath)
+	}
+	return nil
+}
+
+// globalGitHooksDir returns the directory core.hooksPath is pointed at.
+func globalGitHooksDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".git_hooks"), nil
+}
+
 func createGlobalHook(hookPath string) error {
-	templateContent := `#!/bin/sh
+	content := globalHookContent(filepath.Base(hookPath))
+
+	if err := os.WriteFile(hookPath, []byte(content), 0755); err != nil {
+		return err
+	}
+
+	return nil
+}
 
+// globalHookContent renders the wrapper git runs for hookType, which hands
+// over to omnihook and then to the repo-local hook.
+func globalHookContent(hookType string) string {
+	templateContent := `#!/bin/sh
+%s
 # Call Omnihook to run managed hooks
 if command -v omnihook >/dev/null 2>&1; then
 	%s
@@ -116,28 +187,35 @@ fi
 
 # Also run the repo-local hook if it exists
 if [ -f .git/hooks/%s ]; then
-	.git/hooks/%s
+	%s
 	if [ $? -ne 0 ]; then
 		echo "Repo-local hook failed. Aborting commit."
 		exit 1
 	fi
 fi`
 
-	hookType := filepath.Base(hookPath)
-	var omnihookCmd string
-	if hookType == "commit-msg" {
-		omnihookCmd = "commitmsgfile=$1\n\tcommitmsg=$(cat $commitmsgfile)\n\tomnihook run --commit-msg \"$commitmsg\" --type " + hookType
-	} else {
+	var preamble, omnihookCmd string
+	localCmd := ".git/hooks/" + hookType
+	switch hookType {
+	case "commit-msg":
+		omnihookCmd = "omnihook run --commit-msg-file \"$1\" --type " + hookType
+		localCmd = ".git/hooks/commit-msg \"$@\""
+	case "pre-push":
+		// stdin can only be read once, and both hooks need the pushed refs
+		preamble = "\n# git passes the refs being pushed on stdin\npushrefs=$(cat)\n"
+		omnihookCmd = "printf '%s\\n' \"$pushrefs\" | omnihook run --type pre-push --remote \"$1\" --remote-url \"$2\""
+		localCmd = "printf '%s\\n' \"$pushrefs\" | .git/hooks/pre-push \"$@\""
+	default:
 		omnihookCmd = "omnihook run --type " + hookType
+		for i := 1; i <= hookArgCounts[hookType]; i++ {
+			omnihookCmd += fmt.Sprintf(" --hook-arg \"$%d\"", i)
+		}
+		if hookArgCounts[hookType] > 0 {
+			localCmd += " \"$@\""
+		}
 	}
 
-	content := fmt.Sprintf(templateContent, omnihookCmd, hookType, hookType)
-
-	if err := os.WriteFile(hookPath, []byte(content), 0755); err != nil {
-		return err
-	}
-
-	return nil
+	return fmt.Sprintf(templateContent, preamble, omnihookCmd, hookType, localCmd)
 }
 
 func init() {
@@ -4,24 +4,22 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var disableCmd = &cobra.Command{
-	Use:   "disable --id <hook_id> --type <hook_type>",
+	Use:   "disable --id <[alias/]hook_id> [--type <hook_type>]",
 	Short: "Disable a specified hook",
 	RunE:  disableHook,
 }
 
 func init() {
 	rootCmd.AddCommand(disableCmd)
-	disableCmd.Flags().String("id", "", "ID of the hook to disable")
-	disableCmd.Flags().String("type", "", "Type of the hook to disable")
+	disableCmd.Flags().String("id", "", "ID of the hook to disable, as <id> or <alias>/<id>")
+	disableCmd.Flags().String("type", "", "Type of the hook to disable (default: every type the hook is installed for)")
 	disableCmd.MarkFlagRequired("id")
-	disableCmd.MarkFlagRequired("type")
 }
 
 func disableHook(cmd *cobra.Command, args []string) error {
@@ -32,21 +30,33 @@ func disableHook(cmd *cobra.Command, args []string) error {
 
 	hookID, _ := cmd.Flags().GetString("id")
 	hookType, _ := cmd.Flags().GetString("type")
-	hookPath := filepath.Join(hooksDir, hookType, hookID)
-	disabledHookPath := hookPath + ".disabled"
-	
-	if _, err := os.Stat(disabledHookPath); err == nil {
-		fmt.Printf("Hook '%s' is already disabled.\n", hookID)
-		return nil
+	targets, err := hookTargets(hooksDir, hookType, hookID)
+	if err != nil {
+		return err
 	}
-	if _, err := os.Stat(hookPath); os.IsNotExist(err) {
+	if len(targets) == 0 {
 		return fmt.Errorf("hook '%s' not found", hookID)
 	}
 
-	if err := os.Rename(hookPath, disabledHookPath); err != nil {
-		return fmt.Errorf("failed to disable hook '%s': %w", hookID, err)
+	disabled := 0
+	for _, target := range targets {
+		enabledHookPath := hookPath(hooksDir, target.HookType, target.ID)
+		disabledHookPath := enabledHookPath + ".disabled"
+
+		if _, err := os.Lstat(disabledHookPath); err == nil {
+			continue
+		}
+
+		if err := os.Rename(enabledHookPath, disabledHookPath); err != nil {
+			return fmt.Errorf("failed to disable hook '%s': %w", target.ID, err)
+		}
+		disabled++
 	}
 
-	fmt.Printf("Hook '%s' has been disabled.\n", hookID)
+	if disabled == 0 {
+		fmt.Printf("Hook '%s' is already disabled.\n", targets[0].ID)
+		return nil
+	}
+	fmt.Printf("Hook '%s' has been disabled.\n", targets[0].ID)
 	return nil
 }
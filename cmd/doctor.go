@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jwalton/gchalk"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vjayajv/omnihook/utils"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose why hooks are not running",
+	Long: `Check the omnihook setup end to end: the config file, git's core.hooksPath,
+the global wrapper scripts, omnihook being on PATH, and the installed hooks
+themselves. Use --fix to repair what can be repaired automatically.`,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().Bool("fix", false, "Repair the problems that can be fixed automatically")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// Severity of a doctor finding.
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorFinding is the outcome of one diagnostic. Findings with a fix can be
+// repaired by doctor --fix.
+type doctorFinding struct {
+	level   string
+	message string
+	fix     func() error
+}
+
+type doctorCheck struct {
+	name string
+	run  func() []doctorFinding
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	fix, _ := cmd.Flags().GetBool("fix")
+	cmd.SilenceUsage = true
+
+	checks := []doctorCheck{
+		{"Config file", checkConfigFile},
+		{"Hooks directory", checkHooksDir},
+		{"Global core.hooksPath", checkGlobalHooksPath},
+		{"Repository core.hooksPath", checkRepoHooksPath},
+		{"Wrapper scripts", checkWrapperScripts},
+		{"omnihook on PATH", checkOmnihookOnPath},
+		{"Installed hooks", checkInstalledHooks},
+		{"Disabled hooks", checkDisabledHooks},
+	}
+
+	failures, fixed := 0, 0
+	for _, check := range checks {
+		fmt.Println(gchalk.Bold(check.name))
+		for _, finding := range check.run() {
+			if finding.level != doctorOK && fix && finding.fix != nil {
+				if err := finding.fix(); err != nil {
+					fmt.Printf("  ❌ %s (fix failed: %v)\n", finding.message, err)
+					failures++
+				} else {
+					fmt.Printf("  🔧 %s (fixed)\n", finding.message)
+					fixed++
+				}
+				continue
+			}
+
+			switch finding.level {
+			case doctorOK:
+				fmt.Printf("  ✅ %s\n", finding.message)
+			case doctorWarn:
+				fmt.Printf("  ⚠️  %s\n", finding.message)
+			default:
+				fmt.Printf("  ❌ %s\n", finding.message)
+				failures++
+			}
+			if finding.level != doctorOK && finding.fix != nil {
+				fmt.Println("     can be repaired with 'omnihook doctor --fix'")
+			}
+		}
+	}
+
+	fmt.Println()
+	if fixed > 0 {
+		fmt.Printf("Fixed %d problem(s).\n", fixed)
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d problem(s) found", failures)
+	}
+	fmt.Println("No problems found.")
+	return nil
+}
+
+func doctorPass(format string, args ...any) doctorFinding {
+	return doctorFinding{level: doctorOK, message: fmt.Sprintf(format, args...)}
+}
+
+func checkConfigFile() []doctorFinding {
+	configFile := viper.ConfigFileUsed()
+	if configFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return []doctorFinding{{level: doctorFail, message: fmt.Sprintf("cannot determine home directory: %v", err)}}
+		}
+		configFile = filepath.Join(home, ".omnihook", "config.yaml")
+	}
+
+	reconfigure := func() error {
+		configureOmnihook(false)
+		return viper.ReadInConfig()
+	}
+	if _, err := os.Stat(configFile); err != nil {
+		return []doctorFinding{{level: doctorFail, message: fmt.Sprintf("%s not found, run 'omnihook configure'", configFile), fix: reconfigure}}
+	}
+	if viper.GetString("omni_hooks_dir") == "" {
+		return []doctorFinding{{level: doctorFail, message: fmt.Sprintf("omni_hooks_dir is not set in %s", configFile), fix: reconfigure}}
+	}
+	return []doctorFinding{doctorPass("using %s", configFile)}
+}
+
+func checkHooksDir() []doctorFinding {
+	hooksDir := viper.GetString("omni_hooks_dir")
+	if hooksDir == "" {
+		return []doctorFinding{{level: doctorFail, message: "hooks directory is not configured"}}
+	}
+	info, err := os.Stat(hooksDir)
+	if err != nil {
+		return []doctorFinding{{level: doctorFail, message: fmt.Sprintf("%s does not exist", hooksDir), fix: func() error {
+			return os.MkdirAll(hooksDir, 0755)
+		}}}
+	}
+	if !info.IsDir() {
+		return []doctorFinding{{level: doctorFail, message: fmt.Sprintf("%s is not a directory", hooksDir)}}
+	}
+	return []doctorFinding{doctorPass("%s", hooksDir)}
+}
+
+func checkGlobalHooksPath() []doctorFinding {
+	gitHooksDir, err := globalGitHooksDir()
+	if err != nil {
+		return []doctorFinding{{level: doctorFail, message: fmt.Sprintf("cannot determine home directory: %v", err)}}
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return []doctorFinding{{level: doctorFail, message: "git not found on PATH"}}
+	}
+
+	fix := func() error { return setGitHooksPath(gitHooksDir) }
+	current := gitConfigValue("--global", "core.hooksPath")
+	if current == "" {
+		return []doctorFinding{{level: doctorFail, message: "core.hooksPath is not set globally, so git never calls omnihook", fix: fix}}
+	}
+	if utils.ExpandPath(current) != gitHooksDir {
+		return []doctorFinding{{level: doctorFail, message: fmt.Sprintf("core.hooksPath is %s, expected %s", current, gitHooksDir), fix: fix}}
+	}
+	return []doctorFinding{doctorPass("%s", current)}
+}
+
+func checkRepoHooksPath() []doctorFinding {
+	repoRoot, err := utils.GitRepoRoot()
+	if err != nil {
+		return []doctorFinding{doctorPass("not inside a git repository, skipped")}
+	}
+	current := gitConfigValue("--local", "core.hooksPath")
+	if current == "" {
+		return []doctorFinding{doctorPass("not overridden in %s", repoRoot)}
+	}
+	return []doctorFinding{{
+		level:   doctorFail,
+		message: fmt.Sprintf("%s sets core.hooksPath to %s, which bypasses omnihook in this repository", repoRoot, current),
+		fix: func() error {
+			return exec.Command("git", "config", "--local", "--unset", "core.hooksPath").Run()
+		},
+	}}
+}
+
+func checkWrapperScripts() []doctorFinding {
+	gitHooksDir, err := globalGitHooksDir()
+	if err != nil {
+		return []doctorFinding{{level: doctorFail, message: fmt.Sprintf("cannot determine home directory: %v", err)}}
+	}
+
+	// Every configured type needs a wrapper, as does any type hooks are
+	// installed for, or those hooks never run.
+	hookTypes := defaultHookTypes
+	if hooksDir := viper.GetString("omni_hooks_dir"); hooksDir != "" {
+		hookTypes = wrapperHookTypes(hooksDir)
+	}
+
+	var findings []doctorFinding
+	for _, hookType := range hookTypes {
+		wrapperPath := filepath.Join(gitHooksDir, hookType)
+		fix := func() error {
+			if err := os.MkdirAll(gitHooksDir, 0755); err != nil {
+				return err
+			}
+			return createGlobalHook(wrapperPath)
+		}
+
+		content, err := os.ReadFile(wrapperPath)
+		switch {
+		case err != nil:
+			findings = append(findings, doctorFinding{level: doctorFail, message: fmt.Sprintf("%s is missing", wrapperPath), fix: fix})
+		case string(content) != globalHookContent(hookType):
+			findings = append(findings, doctorFinding{level: doctorWarn, message: fmt.Sprintf("%s differs from the current wrapper", wrapperPath), fix: fix})
+		case !isExecutable(wrapperPath):
+			findings = append(findings, doctorFinding{level: doctorFail, message: fmt.Sprintf("%s is not executable", wrapperPath), fix: func() error {
+				return os.Chmod(wrapperPath, 0755)
+			}})
+		default:
+			findings = append(findings, doctorPass("%s", wrapperPath))
+		}
+	}
+	return findings
+}
+
+// checkOmnihookOnPath finds omnihook the way the wrappers do, with
+// command -v in /bin/sh. It can only look at the PATH doctor runs with;
+// git started from an IDE or GUI may run hooks with another one.
+func checkOmnihookOnPath() []doctorFinding {
+	out, err := exec.Command("/bin/sh", "-c", "command -v omnihook").Output()
+	path := strings.TrimSpace(string(out))
+	if err != nil || path == "" {
+		return []doctorFinding{{level: doctorFail, message: "omnihook is not on this shell's PATH, so wrappers run with it silently skip all hooks"}}
+	}
+	self, err := os.Executable()
+	if err == nil {
+		resolvedPath, _ := filepath.EvalSymlinks(path)
+		resolvedSelf, _ := filepath.EvalSymlinks(self)
+		if resolvedPath != resolvedSelf {
+			return []doctorFinding{{level: doctorWarn, message: fmt.Sprintf("wrappers run with this shell's PATH run %s, which is not this omnihook (%s)", path, self)}}
+		}
+	}
+	return []doctorFinding{doctorPass("%s (on this shell's PATH; git run from an IDE or GUI may use another)", path)}
+}
+
+func checkInstalledHooks() []doctorFinding {
+	hooksDir := viper.GetString("omni_hooks_dir")
+	if hooksDir == "" {
+		return []doctorFinding{doctorPass("no hooks directory, skipped")}
+	}
+	types, err := listHookTypes(hooksDir)
+	if err != nil {
+		return []doctorFinding{{level: doctorFail, message: fmt.Sprintf("cannot list hooks: %v", err)}}
+	}
+
+	// The install records say how entry hooks are run
+	cache, _ := readCache()
+
+	var findings []doctorFinding
+	count := 0
+	for _, hookType := range types {
+		files, _ := listHookFiles(hooksDir, hookType)
+		for _, file := range files {
+			if file.Disabled {
+				continue // checked when enabled again
+			}
+			count++
+			path := file.Path
+			label := fmt.Sprintf("%s (%s)", file.ID, hookType)
+
+			info, err := os.Stat(path)
+			if err != nil {
+				findings = append(findings, doctorFinding{level: doctorFail, message: fmt.Sprintf("%s points at a missing file, reinstall it", label)})
+				continue
+			}
+			if info.Mode().Perm()&0111 == 0 {
+				findings = append(findings, doctorFinding{level: doctorFail, message: fmt.Sprintf("%s is not executable", label), fix: func() error {
+					return os.Chmod(path, 0755)
+				}})
+			}
+			if msg := checkShebang(path); msg != "" {
+				findings = append(findings, doctorFinding{level: doctorFail, message: fmt.Sprintf("%s %s", label, msg)})
+				continue
+			}
+			if record, ok := cache.findHook(hookType, file.ID); ok {
+				if err := checkInterpreter(installedHookDefinition(hooksDir, record)); err != nil {
+					findings = append(findings, doctorFinding{level: doctorFail, message: fmt.Sprintf("%s: %v", label, err)})
+				}
+			}
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, doctorPass("%d hook(s) look healthy", count))
+	}
+	return findings
+}
+
+// installedHookDefinition rebuilds as much of an installed hook's definition
+// as checkInterpreter needs from its install record and stored files.
+func installedHookDefinition(hooksDir string, record InstalledHook) Hook {
+	hook := Hook{ID: record.ID, Language: record.Language, Entry: record.Entry, Builtin: record.Builtin}
+	storeDir := hookStoreDir(hooksDir, record.ID)
+	if content, err := os.ReadFile(filepath.Join(storeDir, storeHookFile)); err == nil {
+		hook.Script = string(content)
+	}
+	if target, _, _ := strings.Cut(record.Entry, " "); strings.HasPrefix(target, "./") {
+		if _, err := os.Stat(filepath.Join(storeDir, filepath.FromSlash(target))); err == nil {
+			hook.assetFiles = []hookAsset{{Path: path.Clean(strings.TrimPrefix(target, "./"))}}
+		}
+	}
+	return hook
+}
+
+// checkShebang describes what is wrong with a hook's interpreter line, if
+// anything.
+func checkShebang(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Sprintf("cannot be read: %v", err)
+	}
+	firstLine, _, _ := strings.Cut(string(content), "\n")
+	if !strings.HasPrefix(firstLine, "#!") {
+		return "has no shebang line"
+	}
+
+	interpreter := shebangInterpreter(strings.TrimRight(firstLine, "\r"))
+	if interpreter == "" {
+		return "has an empty shebang line"
+	}
+	if strings.Contains(interpreter, "/") {
+		if _, err := os.Stat(interpreter); err != nil {
+			return fmt.Sprintf("needs %s, which does not exist", interpreter)
+		}
+		return ""
+	}
+	if _, err := exec.LookPath(interpreter); err != nil {
+		return fmt.Sprintf("needs '%s', which is not on PATH", interpreter)
+	}
+	return ""
+}
+
+func checkDisabledHooks() []doctorFinding {
+	hooksDir := viper.GetString("omni_hooks_dir")
+	if hooksDir == "" {
+		return []doctorFinding{doctorPass("no hooks directory, skipped")}
+	}
+	types, err := listHookTypes(hooksDir)
+	if err != nil {
+		return []doctorFinding{{level: doctorFail, message: fmt.Sprintf("cannot list hooks: %v", err)}}
+	}
+	cache, _ := readCache()
+
+	var findings []doctorFinding
+	for _, hookType := range types {
+		files, _ := listHookFiles(hooksDir, hookType)
+		for _, file := range files {
+			if !file.Disabled {
+				continue
+			}
+			enabledPath := hookPath(hooksDir, hookType, file.ID)
+			disabledPath := file.Path
+			label := fmt.Sprintf("%s (%s)", file.ID, hookType)
+
+			if _, err := os.Lstat(enabledPath); err == nil {
+				findings = append(findings, doctorFinding{level: doctorWarn, message: fmt.Sprintf("%s has a stale .disabled copy next to the enabled hook", label), fix: func() error {
+					return os.Remove(disabledPath)
+				}})
+				continue
+			}
+			if _, ok := cache.findHook(hookType, file.ID); !ok {
+				findings = append(findings, doctorFinding{level: doctorWarn, message: fmt.Sprintf("%s is disabled and not recorded as installed, remove it with 'omnihook uninstall'", label)})
+			}
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, doctorPass("no stale disabled hooks"))
+	}
+	return findings
+}
+
+// gitConfigValue reads a git config key from the given scope, returning ""
+// when it is unset.
+func gitConfigValue(scope, key string) string {
+	out, err := exec.Command("git", "config", scope, "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().Perm()&0111 != 0
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctorChecksEntryInterpreters(t *testing.T) {
+	setupInstallDirs(t)
+	source := writeSource(t, `hooks:
+  - id: lint
+    name: Lint
+    description: Runs a node entry
+    language: node
+    entry: lint.js
+`)
+	// A node that exists while the hook is installed and is gone by the
+	// time doctor runs
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "node"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if err := installHook(installOptions{Dir: source, Quiet: true}); err != nil {
+		t.Fatal(err)
+	}
+	findings := checkInstalledHooks()
+	if len(findings) != 1 || findings[0].level != doctorOK {
+		t.Fatalf("checkInstalledHooks() = %+v, want a pass while node is installed", findings)
+	}
+
+	if err := os.Remove(filepath.Join(bin, "node")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	findings = checkInstalledHooks()
+	if len(findings) != 1 || findings[0].level != doctorFail || !strings.Contains(findings[0].message, "'node'") {
+		t.Errorf("checkInstalledHooks() = %+v, want node reported missing", findings)
+	}
+}
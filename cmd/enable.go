@@ -3,24 +3,22 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var enableCmd = &cobra.Command{
-	Use:   "enable --id <hook_id> --type <hook_type>",
+	Use:   "enable --id <[alias/]hook_id> [--type <hook_type>]",
 	Short: "Enable a previously disabled hook",
 	RunE:  enableHook,
 }
 
 func init() {
 	rootCmd.AddCommand(enableCmd)
-	enableCmd.Flags().String("id", "", "ID of the hook to enable")
-	enableCmd.Flags().String("type", "", "Type of the hook to enable")
+	enableCmd.Flags().String("id", "", "ID of the hook to enable, as <id> or <alias>/<id>")
+	enableCmd.Flags().String("type", "", "Type of the hook to enable (default: every type the hook is installed for)")
 	enableCmd.MarkFlagRequired("id")
-	enableCmd.MarkFlagRequired("type")
 }
 
 func enableHook(cmd *cobra.Command, args []string) error {
@@ -31,24 +29,35 @@ func enableHook(cmd *cobra.Command, args []string) error {
 
 	hookID, _ := cmd.Flags().GetString("id")
 	hookType, _ := cmd.Flags().GetString("type")
-	disabledHookPath := filepath.Join(hooksDir, hookType, hookID+".disabled")
-	enabledHookPath := filepath.Join(hooksDir, hookType, hookID)
-
-	// Check if the hook is actually disabled
-	if _, err := os.Stat(disabledHookPath); os.IsNotExist(err) {
-		if _, err := os.Stat(enabledHookPath); err == nil {
-			fmt.Printf("Hook '%s' is already enabled.\n", hookID)
-			return nil
-		}
+	targets, err := hookTargets(hooksDir, hookType, hookID)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
 		return fmt.Errorf("hook '%s' does not exist", hookID)
 	}
 
-	// Rename the disabled hook back to enabled
-	err := os.Rename(disabledHookPath, enabledHookPath)
-	if err != nil {
-		return fmt.Errorf("failed to enable hook '%s': %w", hookID, err)
+	enabled := 0
+	for _, target := range targets {
+		enabledHookPath := hookPath(hooksDir, target.HookType, target.ID)
+		disabledHookPath := enabledHookPath + ".disabled"
+
+		// Check if the hook is actually disabled
+		if _, err := os.Lstat(disabledHookPath); os.IsNotExist(err) {
+			continue
+		}
+
+		// Rename the disabled hook back to enabled
+		if err := os.Rename(disabledHookPath, enabledHookPath); err != nil {
+			return fmt.Errorf("failed to enable hook '%s': %w", target.ID, err)
+		}
+		enabled++
 	}
 
-	fmt.Printf("Hook '%s' has been enabled.\n", hookID)
+	if enabled == 0 {
+		fmt.Printf("Hook '%s' is already enabled.\n", targets[0].ID)
+		return nil
+	}
+	fmt.Printf("Hook '%s' has been enabled.\n", targets[0].ID)
 	return nil
 }
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vjayajv/omnihook/checks"
+)
+
+// maxHistoryOutput caps the output kept per hook run. The end of the output
+// is kept since that is where failures are usually reported.
+const maxHistoryOutput = 4096
+
+// maxHistoryFindings caps the findings of a builtin check kept per hook run.
+const maxHistoryFindings = 100
+
+// resultSkipped is the status of a hook that was skipped rather than run.
+const resultSkipped = "skipped"
+
+// historyRun records one invocation of omnihook run.
+type historyRun struct {
+	Time     time.Time     `json:"time"`
+	Repo     string        `json:"repo,omitempty"`
+	Types    []string      `json:"types"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Hooks    []historyHook `json:"hooks"`
+}
+
+// historyHook is the outcome of one hook within a run.
+type historyHook struct {
+	ID       string        `json:"id"`
+	HookType string        `json:"hookType"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration"`
+	ExitCode int           `json:"exitCode"`
+	Output   string        `json:"output,omitempty"`
+	// Findings locate what a builtin check reported.
+	Findings []checks.Finding `json:"findings,omitempty"`
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the history of hook runs",
+	Long: `Show which hooks ran, when, how long they took and whether they passed,
+most recent first. Filter by repository, hook or status, and use --verbose to
+include the output of each hook.`,
+	RunE: showHistory,
+}
+
+func init() {
+	historyCmd.Flags().String("repo", "", "Only show runs in this repository (default: every repository)")
+	historyCmd.Flags().String("hook", "", "Only show runs of this hook")
+	historyCmd.Flags().String("status", "", "Only show hook runs with this status: passed, failed or skipped")
+	historyCmd.Flags().Int("limit", 20, "Show at most this many hook runs (0 for all)")
+	historyCmd.Flags().BoolP("verbose", "v", false, "Include the output of each hook")
+	historyCmd.Flags().String("output", "table", "Output format: table or json")
+	rootCmd.AddCommand(historyCmd)
+}
+
+// getHistoryPath keeps the run history next to the cache, like the audit log.
+func getHistoryPath() string {
+	return filepath.Join(filepath.Dir(getCacheFilePath()), "history.jsonl")
+}
+
+// newHistoryRun summarizes the results of a run for the history.
+func newHistoryRun(repoRoot string, started time.Time, results []hookResult) historyRun {
+	run := historyRun{
+		Time:     started.UTC(),
+		Repo:     repoRoot,
+		Status:   resultPassed,
+		Duration: time.Since(started).Round(time.Millisecond),
+	}
+	for _, result := range results {
+		if !slices.Contains(run.Types, result.HookType) {
+			run.Types = append(run.Types, result.HookType)
+		}
+		hook := historyHook{
+			ID:       result.ID,
+			HookType: result.HookType,
+			Status:   resultPassed,
+			Duration: result.Duration.Round(time.Millisecond),
+			ExitCode: result.ExitCode,
+			Output:   truncateOutput(result.Output),
+			Findings: result.Findings[:min(len(result.Findings), maxHistoryFindings)],
+		}
+		switch {
+		case result.Skipped:
+			hook.Status = resultSkipped
+		case !result.Passed:
+			hook.Status = resultFailed
+			run.Status = resultFailed
+		}
+		run.Hooks = append(run.Hooks, hook)
+	}
+	return run
+}
+
+func truncateOutput(output string) string {
+	if len(output) <= maxHistoryOutput {
+		return output
+	}
+	return "... (truncated)\n" + output[len(output)-maxHistoryOutput:]
+}
+
+// appendHistory adds a run to the history, which is only ever appended to.
+func appendHistory(run historyRun) error {
+	path := getHistoryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to serialize history: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// readHistory returns every recorded run, oldest first. Lines that cannot be
+// parsed, such as one cut short by a crash, are skipped.
+func readHistory() ([]historyRun, error) {
+	file, err := os.Open(getHistoryPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	var runs []historyRun
+	scanner := bufio.NewScanner(file)
+	// Lines hold the output of every hook in a run
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var run historyRun
+		if err := json.Unmarshal(scanner.Bytes(), &run); err == nil {
+			runs = append(runs, run)
+		}
+	}
+	return runs, scanner.Err()
+}
+
+// historyEntry is a single hook run together with the run it was part of.
+type historyEntry struct {
+	Time time.Time `json:"time"`
+	Repo string    `json:"repo,omitempty"`
+	historyHook
+}
+
+func showHistory(cmd *cobra.Command, args []string) error {
+	repo, _ := cmd.Flags().GetString("repo")
+	hookID, _ := cmd.Flags().GetString("hook")
+	status, _ := cmd.Flags().GetString("status")
+	limit, _ := cmd.Flags().GetInt("limit")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	output, _ := cmd.Flags().GetString("output")
+	if status != "" && status != resultPassed && status != resultFailed && status != resultSkipped {
+		return fmt.Errorf("invalid status '%s' (expected passed, failed or skipped)", status)
+	}
+	if output != "table" && output != "json" {
+		return fmt.Errorf("invalid output format '%s' (expected table or json)", output)
+	}
+	if repo != "" {
+		if abs, err := filepath.Abs(repo); err == nil {
+			repo = abs
+		}
+	}
+
+	runs, err := readHistory()
+	if err != nil {
+		return err
+	}
+
+	// Most recent first
+	var entries []historyEntry
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		if repo != "" && run.Repo != repo {
+			continue
+		}
+		for _, hook := range run.Hooks {
+			if hookID != "" && !matchesHookID(hookID, hook.ID) {
+				continue
+			}
+			if status != "" && hook.Status != status {
+				continue
+			}
+			if !verbose {
+				hook.Output = ""
+				hook.Findings = nil
+			}
+			entries = append(entries, historyEntry{Time: run.Time, Repo: run.Repo, historyHook: hook})
+		}
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	if output == "json" {
+		if entries == nil {
+			entries = []historyEntry{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No hook runs recorded.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tREPO\tTYPE\tHOOK\tSTATUS\tDURATION\tEXIT")
+	for _, entry := range entries {
+		repoName := "-"
+		if entry.Repo != "" {
+			repoName = filepath.Base(entry.Repo)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n", entry.Time.Local().Format("2006-01-02 15:04:05"), repoName,
+			entry.HookType, entry.ID, entry.Status, entry.Duration, entry.ExitCode)
+		if verbose && strings.TrimSpace(entry.Output) != "" {
+			w.Flush()
+			for _, line := range strings.Split(strings.TrimRight(entry.Output, "\n"), "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+	w.Flush()
+	return nil
+}
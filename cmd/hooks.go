@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// validNamePattern restricts hook IDs and source aliases to names that are
+// safe to use as a single path component.
+var validNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// installedHookFile describes a hook file found under the hooks directory.
+type installedHookFile struct {
+	ID       string
+	HookType string
+	Path     string
+	Disabled bool
+}
+
+// namespacedID joins a source alias and a hook ID into "<alias>/<id>".
+func namespacedID(alias, id string) string {
+	if alias == "" {
+		return id
+	}
+	return alias + "/" + id
+}
+
+// hookPath returns the location of an installed hook. Namespaced IDs live in a
+// sub-directory named after the source alias.
+func hookPath(hooksDir, hookType, id string) string {
+	return filepath.Join(hooksDir, hookType, filepath.FromSlash(id))
+}
+
+// hookExists reports whether a hook is installed, enabled or disabled.
+func hookExists(hooksDir, hookType, id string) bool {
+	path := hookPath(hooksDir, hookType, id)
+	if _, err := os.Lstat(path); err == nil {
+		return true
+	}
+	if _, err := os.Lstat(path + ".disabled"); err == nil {
+		return true
+	}
+	return false
+}
+
+// listHookTypes returns the hook type directories present under hooksDir.
+func listHookTypes(hooksDir string) ([]string, error) {
+	entries, err := os.ReadDir(hooksDir)
+	if err != nil {
+		return nil, err
+	}
+	var types []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			types = append(types, entry.Name())
+		}
+	}
+	return types, nil
+}
+
+// listHookFiles returns every hook installed for hookType, descending one level
+// into source alias directories for namespaced hooks.
+func listHookFiles(hooksDir, hookType string) ([]installedHookFile, error) {
+	typeDir := filepath.Join(hooksDir, hookType)
+	entries, err := os.ReadDir(typeDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []installedHookFile
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if !entry.IsDir() {
+			files = append(files, newInstalledHookFile(hookType, "", filepath.Join(typeDir, entry.Name())))
+			continue
+		}
+		nsEntries, err := os.ReadDir(filepath.Join(typeDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, nsEntry := range nsEntries {
+			if nsEntry.IsDir() || strings.HasPrefix(nsEntry.Name(), ".") {
+				continue
+			}
+			files = append(files, newInstalledHookFile(hookType, entry.Name(), filepath.Join(typeDir, entry.Name(), nsEntry.Name())))
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].ID < files[j].ID })
+	return files, nil
+}
+
+func newInstalledHookFile(hookType, alias, path string) installedHookFile {
+	name := filepath.Base(path)
+	disabled := strings.HasSuffix(name, ".disabled")
+	return installedHookFile{
+		ID:       namespacedID(alias, strings.TrimSuffix(name, ".disabled")),
+		HookType: hookType,
+		Path:     path,
+		Disabled: disabled,
+	}
+}
+
+// resolveHookID maps a possibly un-namespaced hook ID to the installed one. An
+// exact match always wins; otherwise a bare ID matches "<alias>/<id>" as long
+// as exactly one source provides it. IDs are validated first, as the result
+// is joined into paths that get renamed and removed.
+func resolveHookID(hooksDir, hookType, id string) (string, error) {
+	segments := strings.Split(id, "/")
+	if len(segments) > 2 {
+		return "", fmt.Errorf("invalid hook ID '%s', expected <id> or <alias>/<id>", id)
+	}
+	for _, segment := range segments {
+		if !validNamePattern.MatchString(segment) {
+			return "", fmt.Errorf("invalid hook ID '%s', expected <id> or <alias>/<id>", id)
+		}
+	}
+	if hookExists(hooksDir, hookType, id) || len(segments) == 2 {
+		return id, nil
+	}
+
+	files, err := listHookFiles(hooksDir, hookType)
+	if err != nil {
+		return "", fmt.Errorf("failed to list hooks: %w", err)
+	}
+
+	var matches []string
+	for _, file := range files {
+		if strings.HasSuffix(file.ID, "/"+id) {
+			matches = append(matches, file.ID)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return id, nil
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("hook ID '%s' is ambiguous for type '%s', use one of: %s", id, hookType, strings.Join(matches, ", "))
+	}
+}
+
+// hookTypesOf returns the git hook types a hook definition applies to.
+func hookTypesOf(hook Hook) []string {
+	if len(hook.HookTypes) > 0 {
+		return hook.HookTypes
+	}
+	if hook.HookType != "" {
+		return []string{hook.HookType}
+	}
+	return []string{"pre-commit"}
+}
+
+// linkHook makes an installed hook run for hookType by linking its type
+// directory entry to the stored executable. Where symlinks are unavailable
+// the executable is copied instead.
+func linkHook(hooksDir, hookType, id string) error {
+	link := hookPath(hooksDir, hookType, id)
+	if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+		return err
+	}
+	// A reinstall replaces a disabled copy rather than leaving both around
+	for _, path := range []string{link, link + ".disabled"} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	target := hookStoreFile(hooksDir, id)
+	relTarget, err := filepath.Rel(filepath.Dir(link), target)
+	if err != nil {
+		return err
+	}
+	if err := os.Symlink(relTarget, link); err == nil {
+		return nil
+	}
+
+	content, err := os.ReadFile(target)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(link, content, 0755)
+}
+
+// unlinkDroppedTypes removes a reinstalled hook from the types its source no
+// longer lists for it.
+func unlinkDroppedTypes(cache *Cache, hooksDir, id, source string, hookTypes []string) {
+	keep := make(map[string]bool)
+	for _, hookType := range hookTypes {
+		keep[hookType] = true
+	}
+	cache.forgetHooks(func(record InstalledHook) bool {
+		if record.ID != id || record.Source != source || keep[record.HookType] {
+			return false
+		}
+		path := hookPath(hooksDir, record.HookType, id)
+		os.Remove(path)
+		os.Remove(path + ".disabled")
+		return true
+	})
+}
+
+// checkHookTypeFlag rejects a --type that is not a git hook type, as it is
+// joined into paths that get renamed and removed.
+func checkHookTypeFlag(hookType string) error {
+	if hookType != "" && !isValidHookType(hookType) {
+		return fmt.Errorf("invalid hook type '%s'", hookType)
+	}
+	return nil
+}
+
+// hookTargets finds the types a hook is installed under, resolving bare IDs
+// per type. Without a hookType every type is searched, so a hook applying to
+// several types is handled as one.
+func hookTargets(hooksDir, hookType, id string) ([]installedHookFile, error) {
+	if err := checkHookTypeFlag(hookType); err != nil {
+		return nil, err
+	}
+	hookTypes := []string{hookType}
+	if hookType == "" {
+		types, err := listHookTypes(hooksDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list hooks: %w", err)
+		}
+		hookTypes = types
+	}
+
+	var targets []installedHookFile
+	for _, t := range hookTypes {
+		resolved, err := resolveHookID(hooksDir, t, id)
+		if err != nil {
+			return nil, err
+		}
+		if !hookExists(hooksDir, t, resolved) {
+			continue
+		}
+		path := hookPath(hooksDir, t, resolved)
+		_, err = os.Lstat(path)
+		targets = append(targets, installedHookFile{ID: resolved, HookType: t, Path: path, Disabled: err != nil})
+	}
+	return targets, nil
+}
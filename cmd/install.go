@@ -10,165 +10,236 @@ import (
 	"os/exec"
 	"path/filepath"
 	"github.com/spf13/viper"
-	"github.com/lianggaoqiang/progress"
 	"slices"
+	"strings"
 )
 
 // installCmd represents the install command
 var installCmd = &cobra.Command{
 	Use:   "install",
-	Short: "Install a new hook from a Git repository or file",
+	Short: "Install hooks from a Git repository, directory, archive or file",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		url, _ := cmd.Flags().GetString("url")
 		filePath, _ := cmd.Flags().GetString("file")
-
-		if url == "" && filePath == "" {
-			return errors.New("either --url or --file must be provided")
+		dir, _ := cmd.Flags().GetString("dir")
+		checksum, _ := cmd.Flags().GetString("sha256")
+		alias, _ := cmd.Flags().GetString("alias")
+		force, _ := cmd.Flags().GetBool("force")
+
+		provided := 0
+		for _, value := range []string{url, filePath, dir} {
+			if value != "" {
+				provided++
+			}
 		}
-		if url != "" && filePath != "" {
-			return errors.New("cannot use both --url and --file at the same time")
+		if provided == 0 {
+			return errors.New("one of --url, --file or --dir must be provided")
+		}
+		if provided > 1 {
+			return errors.New("only one of --url, --file or --dir can be used at a time")
 		}
 
-		err := installHook(url, filePath)
-		if err == nil && url != "" {
-			updateCache(url)
+		opts := installOptions{URL: url, File: filePath, Dir: dir, Checksum: checksum, Alias: alias, Force: force}
+		err := installHook(opts)
+		if err != nil {
+			return err
 		}
-		return err
+		if url != "" || dir != "" {
+			if err := updateCache(opts.source(), checksum); err != nil {
+				return fmt.Errorf("failed to record source: %w", err)
+			}
+		}
+		return ensureWrappers(getHooksDir())
 	},
 }
 
 type Hook struct {
-	ID          string `yaml:"id"`
-	Name        string `yaml:"name"`
-	Description string `yaml:"description"`
-	Script      string `yaml:"script"`
-	ScriptPath  string `yaml:"scriptPath"`
-	HookType    string `yaml:"hookType"`
+	ID          string   `yaml:"id"`
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Script      string   `yaml:"script"`
+	ScriptPath  string   `yaml:"scriptPath"`
+	HookType    string   `yaml:"hookType"`
+	HookTypes   []string `yaml:"hookTypes"`
+	Language    string   `yaml:"language"`
+	Entry       string   `yaml:"entry"`
+	Builtin     string   `yaml:"builtin"`
+	Assets      []string `yaml:"assets"`
+	Tags        []string `yaml:"tags"`
+	// Required hooks cannot be skipped with OMNIHOOK_SKIP or run --skip.
+	Required bool `yaml:"required"`
+
+	HookSettings `yaml:",inline"`
+
+	// assetFiles holds the content of Assets, read while the source is still
+	// available so it can be copied into the hook's directory on install.
+	assetFiles []hookAsset
+	// position locates the hook's definition as file:line:column.
+	position string
+	// sourceRef pins the revision of the source the hook was loaded from,
+	// such as the commit of a cloned repository.
+	sourceRef string
 }
 
 type OmniHook struct {
-	Hooks []Hook `yaml:"hooks"`
+	SchemaVersion int    `yaml:"schemaVersion"`
+	Hooks         []Hook `yaml:"hooks"`
 }
 
-func installHook(url, filePath string) error {
+// installOptions describes where hooks are installed from and how conflicts
+// with already installed hooks are handled.
+type installOptions struct {
+	URL      string
+	File     string
+	Dir      string
+	Checksum string
+	Alias    string
+	Force    bool
+	// Ref checks out a branch, tag or commit of a git source rather than
+	// its default branch.
+	Ref string
+	// Quiet installs without drawing progress bars.
+	Quiet bool
+}
+
+// source returns the identifier recorded in the cache for the hooks' origin.
+func (o installOptions) source() string {
+	if o.URL != "" {
+		return o.URL
+	}
+	path := o.File
+	if o.Dir != "" {
+		path = o.Dir
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func installHook(opts installOptions) error {
 	hooksDir := getHooksDir()
 	if hooksDir == "" {
 		return errors.New("hooks directory not set. Run 'omnihook configure' first")
 	}
 
-	var hooks []Hook
-	var err error
-
-	if url != "" {
-		hooks, err = fetchHooksFromGitRepo(url)
-	} else {
-		hooks, err = loadHooksFromFile(filePath)
+	cache, err := readCache()
+	if err != nil {
+		return err
+	}
+	source := opts.source()
+	alias, err := resolveAlias(cache, opts.Alias, source)
+	if err != nil {
+		return err
 	}
 
+	hooks, err := fetchHooks(opts)
 	if err != nil {
 		return fmt.Errorf("failed to load hooks: %w", err)
 	}
 
+	if err := checkCollisions(cache, hooksDir, hooks, alias, source, opts.Force); err != nil {
+		return err
+	}
+
+	// Every hook is checked before any is written, so that a bad one does
+	// not leave the others half installed
+	var invalid []error
+	for _, hook := range hooks {
+		err := validateHook(hook)
+		if err == nil {
+			err = checkInterpreter(hook)
+		}
+		if err != nil {
+			invalid = append(invalid, fmt.Errorf("invalid hook configuration at %s: %w", hook.position, err))
+		}
+	}
+	if len(invalid) > 0 {
+		return errors.Join(invalid...)
+	}
+
+	installedAt := time.Now().UTC().Truncate(time.Second)
 	maxHookNameLength := 0
 	hookNames := make([]string, len(hooks))
 	for i, hook := range hooks {
-		hookNames[i] = hook.ID
+		hookNames[i] = namespacedID(alias, hook.ID)
 		if len(hookNames[i]) > maxHookNameLength {
 			maxHookNameLength = len(hookNames[i])
 		}
 	}
 
-	// Create a progress manager
-	p := progress.Start()
-
 	// Create progress bars for each hook
-	bars := make(map[string]*progress.DefaultBar)
+	bars := newHookProgress(opts.Quiet)
 	for _, hookName := range hookNames {
-		paddedHookName := fmt.Sprintf("🪝 Installing hook %-*s ", maxHookNameLength, hookName)
-		bars[hookName] = progress.NewBar().Custom(progress.BarSetting{
-			Total:          15,
-			StartText:      paddedHookName,
-			EndText:        " ✅",
-			NotPassedText:  progress.BlackText("▇"),
-			PassedText:     progress.WhiteText("▇"),
-		})
-		p.AddBar(bars[hookName])
+		bars.add(hookName, fmt.Sprintf("🪝 Installing hook %-*s ", maxHookNameLength, hookName))
 	}
 
+	var installErr error
 	for _, hook := range hooks {
-		if err := validateHook(hook); err != nil {
-			for i := 0; i < 100; i += 20 {
-				bars[hook.ID].Show()
-				bars[hook.ID].Inc()
-				bars[hook.ID].Add(1.4)
-				bars[hook.ID].Setting.EndText = " ❌"
-				bars[hook.ID].Percent(float64(i))
-				bars[hook.ID].Hide()
-				time.Sleep(100 * time.Millisecond)
-			}
-			return fmt.Errorf("invalid hook configuration: %w", err)
+		installID := namespacedID(alias, hook.ID)
+		installErr = writeHook(&cache, hooksDir, installID, source, hook, installedAt)
+		bars.finish(installID, installErr == nil)
+		if installErr != nil {
+			break
 		}
+	}
 
-		if hook.HookType == "" {
-			hook.HookType = "pre-commit"
-		}
-		hookTypeDir := filepath.Join(hooksDir, hook.HookType)
-		if err := os.MkdirAll(hookTypeDir, 0755); err != nil {
-			return fmt.Errorf("failed to create hook type directory: %w", err)
-		}
-		hookFilePath := filepath.Join(hookTypeDir, hook.ID)
-		content := fmt.Sprintf("#!/bin/sh\n# %s\n", hook.Description)
-		if hook.Script != "" {
-			content += fmt.Sprintf("%s\n", hook.Script)
-		} else {
-			content += fmt.Sprintf("exec %s \"$@\"\n", hook.ScriptPath)
+	// Hooks written before a failure are recorded as well, so that they
+	// can be installed again once the failure is fixed
+	if alias != "" {
+		if cache.Aliases == nil {
+			cache.Aliases = map[string]string{}
 		}
+		cache.Aliases[alias] = source
+	}
+	if err := writeCache(cache); err != nil {
+		return errors.Join(installErr, err)
+	}
 
+	return installErr
+}
 
-		if err := os.WriteFile(hookFilePath, []byte(content), 0755); err != nil {
-			for i := 0; i < 100; i += 20 {
-				bars[hook.ID].Show()
-				bars[hook.ID].Inc()
-				bars[hook.ID].Add(1.4)
-				bars[hook.ID].Setting.EndText = " ❌"
-				bars[hook.ID].Percent(float64(i))
-				bars[hook.ID].Hide()
-				time.Sleep(100 * time.Millisecond)
-			}
-			return fmt.Errorf("failed to write hook file: %w", err)
-		}
+// writeHook stores a validated hook, links it from each of its types and
+// records it in the cache.
+func writeHook(cache *Cache, hooksDir, installID, source string, hook Hook, installedAt time.Time) error {
+	hookTypes := hookTypesOf(hook)
+	storeDir := hookStoreDir(hooksDir, installID)
+	content, extraAssets := buildHookContent(hook, storeDir)
 
-		// Explicitly set executable permissions
-		if err := os.Chmod(hookFilePath, 0755); err != nil {
-			for i := 0; i < 100; i += 20 {
-				bars[hook.ID].Show()
-				bars[hook.ID].Inc()
-				bars[hook.ID].Add(1.4)
-				bars[hook.ID].Setting.EndText = " ❌"
-				bars[hook.ID].Percent(float64(i))
-				bars[hook.ID].Hide()
-				time.Sleep(100 * time.Millisecond)
-			}
-			return fmt.Errorf("failed to set executable permissions: %w", err)
-		}
+	// The hook is written once and linked from each type it applies to
+	if err := writeHookStore(storeDir, []byte(content), append(hook.assetFiles, extraAssets...)); err != nil {
+		return fmt.Errorf("failed to install hook '%s': %w", installID, err)
+	}
 
-		for i := 0; i < 100; i += 20 {
-			bars[hook.ID].Show()
-			bars[hook.ID].Inc()
-			bars[hook.ID].Add(1.4)
-			bars[hook.ID].Setting.EndText = " ✅"
-			bars[hook.ID].Percent(float64(i))
-			bars[hook.ID].Hide()
-			time.Sleep(100 * time.Millisecond)
+	for _, hookType := range hookTypes {
+		if err := linkHook(hooksDir, hookType, installID); err != nil {
+			return fmt.Errorf("failed to install hook '%s' for type '%s': %w", installID, hookType, err)
 		}
-		bars[hook.ID].Show()
-		bars[hook.ID].Percent(100)
+		// A reinstall keeps the outcome of the hook's last run
+		previous, _ := cache.findHook(hookType, installID)
+		cache.recordHook(InstalledHook{
+			ID:           installID,
+			HookType:     hookType,
+			Source:       source,
+			Ref:          hook.sourceRef,
+			Name:         hook.Name,
+			Description:  hook.Description,
+			Language:     hookLanguage(hook),
+			Entry:        hook.Entry,
+			Builtin:      hook.Builtin,
+			InstalledAt:  installedAt,
+			Checksum:     contentChecksum([]byte(content)),
+			Tags:         hook.Tags,
+			Required:     hook.Required,
+			LastResult:   previous.LastResult,
+			HookSettings: hook.HookSettings,
+		})
 	}
-
+	unlinkDroppedTypes(cache, hooksDir, installID, source, hookTypes)
 	return nil
 }
 
-func fetchHooksFromGitRepo(repoURL string) ([]Hook, error) {
+func fetchHooksFromGitRepo(repoURL, ref string) ([]Hook, error) {
 	tempDir, err := os.MkdirTemp("", "omnihook-clone-")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
@@ -180,30 +251,49 @@ func fetchHooksFromGitRepo(repoURL string) ([]Hook, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to clone repository: %s: %w", string(output), err)
 	}
+	if ref != "" {
+		output, err := exec.Command("git", "-C", tempDir, "checkout", "--quiet", "--detach", ref).CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check out '%s': %s: %w", ref, strings.TrimSpace(string(output)), err)
+		}
+	}
 
+	hooks, err := fetchHooksFromDir(tempDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(hooks) == 0 {
+		return nil, errors.New("no valid hook configurations found in repository")
+	}
+
+	if head, err := exec.Command("git", "-C", tempDir, "rev-parse", "HEAD").Output(); err == nil {
+		for i := range hooks {
+			hooks[i].sourceRef = strings.TrimSpace(string(head))
+		}
+	}
+	return hooks, nil
+}
+
+// fetchHooksFromDir loads every omnihook.yml found under dir.
+func fetchHooksFromDir(dir string) ([]Hook, error) {
 	var hooks []Hook
-	err = filepath.Walk(tempDir, func(path string, info os.FileInfo, err error) error {
+	var loadErrs []error
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 		if filepath.Base(path) == "omnihook.yml" {
 			loadedHooks, err := loadHooksFromFile(path)
 			if err != nil {
-				return err // Return immediately if a hook file has errors
-			}
-			for i, hook := range loadedHooks {
-				if hook.ScriptPath != "" {
-					scriptFullPath := filepath.Join(filepath.Dir(path), hook.ScriptPath)
-					scriptContent, err := os.ReadFile(scriptFullPath)
-					if err != nil {
-						return fmt.Errorf("failed to read script file %s: %w", scriptFullPath, err)
-					}
-					loadedHooks[i].Script = string(scriptContent)
-					loadedHooks[i].ScriptPath = ""
-				}
+				// Keep going so every broken hook file is reported at once
+				loadErrs = append(loadErrs, err)
+				return nil
 			}
 			hooks = append(hooks, loadedHooks...)
 		}
@@ -213,43 +303,86 @@ func fetchHooksFromGitRepo(repoURL string) ([]Hook, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error scanning repository files: %w", err)
 	}
-
-	if len(hooks) == 0 {
-		return nil, errors.New("no valid hook configurations found in repository")
+	if len(loadErrs) > 0 {
+		return nil, errors.Join(loadErrs...)
 	}
 
 	return hooks, nil
 }
 
+// loadHooksFromFile parses a hook file, inlining scriptPath and reading assets
+// relative to the directory the file lives in.
 func loadHooksFromFile(filePath string) ([]Hook, error) {
+	hooks, err := parseHooksFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	baseDir, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", filePath, err)
+	}
+	for i := range hooks {
+		if err := resolveHookFiles(&hooks[i], baseDir); err != nil {
+			return nil, fmt.Errorf("%s: hook '%s': %w", hooks[i].position, hooks[i].ID, err)
+		}
+	}
+	return hooks, nil
+}
+
+func parseHooksFile(filePath string) ([]Hook, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// Try parsing as OmniHook first
-	var omniHook OmniHook
-	if err := yaml.Unmarshal(data, &omniHook); err == nil {
-		if len(omniHook.Hooks) > 0 {
-			return omniHook.Hooks, nil
+	return decodeHooks(filePath, data)
+}
+
+// resolveHookFiles reads the files a hook references so it no longer depends
+// on the directory it was loaded from.
+func resolveHookFiles(hook *Hook, baseDir string) error {
+	// Hooks setting both are rejected by validateHook
+	if hook.ScriptPath != "" && hook.Script == "" {
+		scriptFullPath := hook.ScriptPath
+		if !filepath.IsAbs(scriptFullPath) {
+			scriptFullPath = filepath.Join(baseDir, scriptFullPath)
+		}
+		scriptContent, err := os.ReadFile(scriptFullPath)
+		if err != nil {
+			return fmt.Errorf("failed to read script file %s: %w", scriptFullPath, err)
 		}
+		hook.Script = string(scriptContent)
+		hook.ScriptPath = ""
 	}
 
-	// Reset error and try parsing as single Hook
-	var hook Hook
-	if err := yaml.Unmarshal(data, &hook); err == nil && hook.ID != "" {
-		return []Hook{hook}, nil
+	assets, err := loadAssets(baseDir, hook.Assets)
+	if err != nil {
+		return err
 	}
-
-	return nil, errors.New("failed to parse YAML: file may be improperly formatted")
+	hook.assetFiles = assets
+	return nil
 }
 
 func validateHook(hook Hook) error {
 	if hook.ID == "" {
 		return errors.New("hook ID is required")
 	}
-	if hook.HookType == "" {
-		hook.HookType = "pre-commit"
+	if !validNamePattern.MatchString(hook.ID) {
+		return fmt.Errorf("hook ID '%s' may only contain letters, digits, '.', '_' and '-'", hook.ID)
+	}
+	if hook.HookType != "" && len(hook.HookTypes) > 0 {
+		return errors.New("hook cannot have both hookType and hookTypes")
+	}
+	seenTypes := make(map[string]bool)
+	for _, hookType := range hookTypesOf(hook) {
+		if !isValidHookType(hookType) {
+			return fmt.Errorf("invalid hook type '%s'", hookType)
+		}
+		if seenTypes[hookType] {
+			return fmt.Errorf("hook type '%s' is listed more than once", hookType)
+		}
+		seenTypes[hookType] = true
 	}
 	if hook.Name == "" {
 		return errors.New("hook name is required")
@@ -257,12 +390,81 @@ func validateHook(hook Hook) error {
 	if hook.Description == "" {
 		return errors.New("hook description is required")
 	}
-	if hook.Script == "" && hook.ScriptPath == "" {
-		return errors.New("either script or scriptPath must be provided")
-	}
 	if hook.Script != "" && hook.ScriptPath != "" {
 		return errors.New("hook cannot have both script and scriptPath")
 	}
+	for _, tag := range hook.Tags {
+		if !validNamePattern.MatchString(tag) {
+			return fmt.Errorf("tag '%s' may only contain letters, digits, '.', '_' and '-'", tag)
+		}
+	}
+	if hook.Builtin != "" {
+		return validateBuiltin(hook)
+	}
+	return validateLanguage(hook)
+}
+
+// resolveAlias returns the alias hooks from source are namespaced under. An
+// explicit alias must not already belong to another source; without one, the
+// alias registered by a previous install of the same source is reused.
+func resolveAlias(cache Cache, alias, source string) (string, error) {
+	if alias == "" {
+		return cache.aliasForSource(source), nil
+	}
+	if !validNamePattern.MatchString(alias) {
+		return "", fmt.Errorf("alias '%s' may only contain letters, digits, '.', '_' and '-'", alias)
+	}
+	if existing, ok := cache.Aliases[alias]; ok && existing != source {
+		return "", fmt.Errorf("alias '%s' is already used by %s", alias, existing)
+	}
+	// Aliases become directories next to un-namespaced hooks
+	if cache.hasHookID(alias) {
+		return "", fmt.Errorf("alias '%s' conflicts with an installed hook of the same ID", alias)
+	}
+	return alias, nil
+}
+
+// checkCollisions refuses to install a hook over one provided by a different
+// source, and catches sources that define the same hook twice.
+func checkCollisions(cache Cache, hooksDir string, hooks []Hook, alias, source string, force bool) error {
+	seen := make(map[string]bool)
+	for _, hook := range hooks {
+		if !validNamePattern.MatchString(hook.ID) {
+			continue // reported by validateHook
+		}
+		installID := namespacedID(alias, hook.ID)
+
+		if _, ok := cache.Aliases[installID]; ok {
+			return fmt.Errorf("hook ID '%s' conflicts with a source alias of the same name; use --alias to namespace this source", installID)
+		}
+
+		// A hook is stored once whatever its types, so IDs must be unique
+		if seen[installID] {
+			return fmt.Errorf("hook '%s' is defined more than once in %s; use hookTypes to apply one hook to several types", hook.ID, source)
+		}
+		seen[installID] = true
+
+		for _, hookType := range hookTypesOf(hook) {
+			if force || !hookExists(hooksDir, hookType, installID) {
+				continue
+			}
+			record, ok := cache.findHook(hookType, installID)
+			if ok && record.Source == source {
+				continue
+			}
+			// Hooks installed before sources were recorded per hook are
+			// taken to belong to a source the cache already knows, so
+			// updating it keeps working
+			if !ok && slices.Contains(cache.Sources, source) {
+				continue
+			}
+			owner := "an unknown source"
+			if ok {
+				owner = record.Source
+			}
+			return fmt.Errorf("hook '%s' of type '%s' is already installed from %s; use --alias to namespace this source or --force to overwrite", installID, hookType, owner)
+		}
+	}
 	return nil
 }
 
@@ -283,6 +485,10 @@ func getHooksDir() string {
 }
 
 func getCacheFilePath() string {
+	// Set to keep an install separate from the user's, as omnihook ci does
+	if cacheFile := viper.GetString("omni_cache_file"); cacheFile != "" {
+		return cacheFile
+	}
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "cache.yml" // Fallback to current directory
@@ -310,17 +516,26 @@ func readCache() (Cache, error) {
 	return cache, nil
 }
 
-func updateCache(url string) error {
+func updateCache(url, checksum string) error {
 	cache, err := readCache()
 	if err != nil {
 		return err
 	}
 
-	if slices.Contains(cache.Sources, url) {
-			return nil // URL already exists, no need to update
+	// Keep the pinned checksum in step with what was last installed
+	if checksum != "" {
+		if cache.Checksums == nil {
+			cache.Checksums = map[string]string{}
+		}
+		cache.Checksums[url] = checksum
+	} else {
+		delete(cache.Checksums, url)
+	}
+
+	if !slices.Contains(cache.Sources, url) {
+		cache.Sources = append(cache.Sources, url)
 	}
 
-	cache.Sources = append(cache.Sources, url)
 	return writeCache(cache)
 }
 
@@ -345,7 +560,11 @@ func writeCache(cache Cache) error {
 }
 
 func init() {
-	installCmd.Flags().String("url", "", "Git repository URL of the hooks")
-	installCmd.Flags().String("file", "", "Path to the local hook configuration file")
+	installCmd.Flags().String("url", "", "Git repository URL of the hooks, or an HTTP(S) URL to a .tar.gz/.zip archive or YAML file")
+	installCmd.Flags().String("file", "", "Path to the local hook configuration file or .tar.gz/.zip archive")
+	installCmd.Flags().String("dir", "", "Local directory to scan for omnihook.yml files")
+	installCmd.Flags().String("sha256", "", "Expected SHA-256 checksum of the archive or YAML file (not supported for --dir or Git repositories)")
+	installCmd.Flags().String("alias", "", "Namespace hooks from this source as <alias>/<id>")
+	installCmd.Flags().Bool("force", false, "Overwrite hooks with the same ID installed from another source")
 	rootCmd.AddCommand(installCmd)
 }
\ No newline at end of file
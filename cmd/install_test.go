@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// setupInstallDirs points omnihook at an empty hooks directory and cache of
+// its own and returns the hooks directory.
+func setupInstallDirs(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	hooksDir := filepath.Join(dir, "hooks")
+	if err := os.Mkdir(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("omni_hooks_dir", hooksDir)
+	viper.Set("omni_cache_file", filepath.Join(dir, "cache.yml"))
+	t.Cleanup(func() {
+		viper.Set("omni_hooks_dir", "")
+		viper.Set("omni_cache_file", "")
+	})
+	return hooksDir
+}
+
+// writeSource creates a directory holding an omnihook.yml with content.
+func writeSource(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "omnihook.yml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestInstallOverLegacyHooks(t *testing.T) {
+	hooksDir := setupInstallDirs(t)
+	source := writeSource(t, testHookFile)
+
+	// Before hooks were recorded in the cache, a hook was a plain file in
+	// its type's directory and the cache only listed sources
+	if err := os.MkdirAll(filepath.Join(hooksDir, "pre-commit"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hooksDir, "pre-commit", "lint"), []byte("#!/bin/sh\necho old\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCache(Cache{Sources: []string{source}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another source is still refused, as nothing says it owns the hook
+	other := writeSource(t, testHookFile)
+	err := installHook(installOptions{Dir: other, Quiet: true})
+	if err == nil || !strings.Contains(err.Error(), "already installed from an unknown source") {
+		t.Fatalf("installHook() from another source error = %v, want a collision", err)
+	}
+
+	cache, err := readCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reinstallHooks(cache, cache.Sources); err != nil {
+		t.Fatalf("reinstallHooks() error = %v", err)
+	}
+	cache, err = readCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record, ok := cache.findHook("pre-commit", "lint"); !ok || record.Source != source {
+		t.Errorf("lint is recorded as %+v, %v, want it owned by %s", record, ok, source)
+	}
+	content, err := os.ReadFile(filepath.Join(hooksDir, "pre-commit", "lint"))
+	if err != nil || !strings.Contains(string(content), "echo lint") {
+		t.Errorf("lint = %q, %v, want the reinstalled hook", content, err)
+	}
+}
+
+func TestReinstallHooksReportsFailures(t *testing.T) {
+	setupInstallDirs(t)
+	good := writeSource(t, testHookFile)
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	err := reinstallHooks(Cache{}, []string{missing, good})
+	if err == nil || !strings.Contains(err.Error(), missing) {
+		t.Fatalf("reinstallHooks() error = %v, want the missing source reported", err)
+	}
+	cache, err := readCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.findHook("pre-commit", "lint"); !ok {
+		t.Errorf("sources after a failed one were not updated")
+	}
+}
+
+func TestInstallValidatesBeforeWriting(t *testing.T) {
+	hooksDir := setupInstallDirs(t)
+	source := writeSource(t, `hooks:
+  - id: first
+    name: First
+    description: Valid
+    script: echo first
+  - id: second
+    name: Second
+    description: Unknown language
+    language: bogus
+    entry: bogus
+`)
+
+	err := installHook(installOptions{Dir: source, Quiet: true})
+	if err == nil || !strings.Contains(err.Error(), "invalid hook configuration") {
+		t.Fatalf("installHook() error = %v, want the invalid hook reported", err)
+	}
+	if hookExists(hooksDir, "pre-commit", "first") {
+		t.Errorf("first was installed although its source has an invalid hook")
+	}
+}
+
+func TestInstallRecordsHooksWrittenBeforeFailure(t *testing.T) {
+	hooksDir := setupInstallDirs(t)
+	source := writeSource(t, `hooks:
+  - id: first
+    name: First
+    description: Written
+    script: echo first
+  - id: second
+    name: Second
+    description: Cannot be linked
+    script: echo second
+`)
+	// A non-empty directory where second is linked makes linking it fail
+	blocker := filepath.Join(hooksDir, "pre-commit", "second", "x")
+	if err := os.MkdirAll(blocker, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := installHook(installOptions{Dir: source, Force: true, Quiet: true}); err == nil {
+		t.Fatal("installHook() succeeded, want second to fail")
+	}
+	cache, err := readCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.findHook("pre-commit", "first"); !ok {
+		t.Fatalf("first was written but not recorded")
+	}
+
+	// Once fixed, the source installs again without --force
+	if err := os.RemoveAll(filepath.Join(hooksDir, "pre-commit", "second")); err != nil {
+		t.Fatal(err)
+	}
+	if err := installHook(installOptions{Dir: source, Quiet: true}); err != nil {
+		t.Fatalf("installHook() after fixing the failure error = %v", err)
+	}
+}
+
+func TestHookTargetsRejectsInvalidTypes(t *testing.T) {
+	hooksDir := setupInstallDirs(t)
+	for _, hookType := range []string{"../x", ".store", "pre-commit/lint", "bogus"} {
+		if _, err := hookTargets(hooksDir, hookType, "lint"); err == nil || !strings.Contains(err.Error(), "invalid hook type") {
+			t.Errorf("hookTargets(%q) error = %v, want the type rejected", hookType, err)
+		}
+		if err := uninstallHookType(hooksDir, hookType); err == nil || !strings.Contains(err.Error(), "invalid hook type") {
+			t.Errorf("uninstallHookType(%q) error = %v, want the type rejected", hookType, err)
+		}
+	}
+}
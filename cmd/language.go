@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Supported values for Hook.Language.
+const (
+	languageShell  = "shell"  // inline shell script, run by /bin/sh unless it has its own shebang
+	languagePython = "python" // python3 script, or entry file among the assets
+	languageNode   = "node"   // node script, or entry file among the assets
+	languageGoRun  = "go-run" // go run of a single-file script or of the entry package
+	languageBinary = "binary" // executable named by entry, found on PATH or among the assets
+	// executable script carrying its own shebang
+	languageExecutable = "executable-with-shebang"
+)
+
+// languageBuiltin is recorded for hooks running a check built into omnihook.
+// It is implied by builtin and cannot be set as a hook's language.
+const languageBuiltin = "builtin"
+
+var validLanguages = []string{languageShell, languagePython, languageNode, languageGoRun, languageBinary, languageExecutable}
+
+// defaultInterpreters maps languages to the command their scripts run with.
+var defaultInterpreters = map[string]string{
+	languagePython: "python3",
+	languageNode:   "node",
+	languageGoRun:  "go",
+}
+
+// hashCommentInterpreters are the interpreters for which a line starting
+// with "#" is a comment, ignoring version suffixes such as python3.12.
+var hashCommentInterpreters = []string{"sh", "bash", "dash", "ksh", "zsh", "python", "ruby", "perl"}
+
+// goScriptAsset is the file name a Go script is stored under in the hook's
+// directory so it can be passed to go run.
+const goScriptAsset = "main.go"
+
+func hookLanguage(hook Hook) string {
+	if hook.Builtin != "" {
+		return languageBuiltin
+	}
+	if hook.Language == "" {
+		return languageShell
+	}
+	return hook.Language
+}
+
+// validateLanguage checks that a hook provides what its language needs.
+func validateLanguage(hook Hook) error {
+	language := hookLanguage(hook)
+	hasScript := hook.Script != "" || hook.ScriptPath != ""
+
+	switch language {
+	case languageShell:
+		if !hasScript {
+			return errors.New("either script or scriptPath must be provided")
+		}
+	case languageExecutable:
+		if !hasScript {
+			return errors.New("either script or scriptPath must be provided")
+		}
+		if hook.Script != "" && !strings.HasPrefix(hook.Script, "#!") {
+			return fmt.Errorf("%s hooks must start with a shebang line", language)
+		}
+	case languagePython, languageNode, languageGoRun:
+		if !hasScript && hook.Entry == "" {
+			return fmt.Errorf("%s hooks need a script, scriptPath or entry", language)
+		}
+		if hasScript && hook.Entry != "" {
+			return errors.New("hook cannot have both a script and an entry")
+		}
+	case languageBinary:
+		if hook.Entry == "" {
+			return errors.New("binary hooks need an entry")
+		}
+		if hasScript {
+			return errors.New("binary hooks cannot have a script")
+		}
+	default:
+		return fmt.Errorf("unsupported language '%s', must be one of: %s", language, strings.Join(validLanguages, ", "))
+	}
+	return nil
+}
+
+// buildHookContent renders the executable installed for a hook. Scripts keep
+// their own shebang when they have one; entry based hooks become a small
+// wrapper that execs the right interpreter. Go scripts are returned as an
+// extra asset since go run needs a .go file.
+func buildHookContent(hook Hook, storeDir string) (string, []hookAsset) {
+	if hook.Builtin != "" {
+		return builtinStub(hook), nil
+	}
+	header := fmt.Sprintf("# %s\n", hook.Description)
+	language := hookLanguage(hook)
+
+	if hook.Entry != "" || language == languageGoRun {
+		entry := hook.Entry
+		if entry == "" {
+			entry = "./" + goScriptAsset
+		}
+		command := entryCommand(language, entry, storeDir)
+		content := "#!/bin/sh\n" + header + "exec " + command + " \"$@\"\n"
+
+		var extra []hookAsset
+		if hook.Entry == "" {
+			extra = append(extra, hookAsset{Path: goScriptAsset, Data: []byte(hook.Script), Mode: 0644})
+		}
+		return content, extra
+	}
+
+	shebang, body := splitShebang(hook.Script)
+	if shebang == "" {
+		shebang = "#!/bin/sh"
+		if interpreter, ok := defaultInterpreters[language]; ok {
+			shebang = "#!/usr/bin/env " + interpreter
+		}
+	}
+	// The description is kept in the install records; it only goes into
+	// the script where the interpreter takes it for a comment
+	if !hashComments(shebang) {
+		header = ""
+	}
+	return shebang + "\n" + header + body + "\n", nil
+}
+
+// hashComments reports whether the interpreter of a shebang line treats
+// lines starting with "#" as comments.
+func hashComments(shebang string) bool {
+	name := path.Base(shebangInterpreter(shebang))
+	return slices.Contains(hashCommentInterpreters, strings.TrimRight(name, "0123456789."))
+}
+
+// entryCommand returns the shell command running an entry. Entries starting
+// with "./" refer to files among the hook's assets.
+func entryCommand(language, entry, storeDir string) string {
+	target, args, _ := strings.Cut(entry, " ")
+	if strings.HasPrefix(target, "./") {
+		target = shellQuote(filepath.Join(storeDir, filepath.FromSlash(target)))
+	}
+	if args != "" {
+		target += " " + args
+	}
+	if language == languageGoRun {
+		return "go run " + target
+	}
+	if interpreter, ok := defaultInterpreters[language]; ok {
+		return interpreter + " " + target
+	}
+	return target
+}
+
+// splitShebang separates a leading "#!" line from the rest of a script.
+func splitShebang(script string) (string, string) {
+	if !strings.HasPrefix(script, "#!") {
+		return "", script
+	}
+	line, rest, _ := strings.Cut(script, "\n")
+	return strings.TrimRight(line, "\r"), rest
+}
+
+// checkInterpreter makes sure whatever runs the hook is available, so a
+// missing interpreter is reported at install time rather than on commit.
+func checkInterpreter(hook Hook) error {
+	if hook.Builtin != "" {
+		return nil
+	}
+	language := hookLanguage(hook)
+
+	var entryFile string
+	if fields := strings.Fields(hook.Entry); len(fields) > 0 && strings.HasPrefix(fields[0], "./") {
+		entryFile = fields[0]
+		if !hasAsset(hook, strings.TrimPrefix(entryFile, "./")) {
+			return fmt.Errorf("entry %s is not among the hook's assets", entryFile)
+		}
+	}
+
+	var command string
+	switch {
+	case language == languageBinary:
+		if entryFile != "" {
+			return nil
+		}
+		command = strings.Fields(hook.Entry)[0]
+	case hook.Entry != "" || language == languageGoRun:
+		command = defaultInterpreters[language]
+	default:
+		shebang, _ := splitShebang(hook.Script)
+		command = shebangInterpreter(shebang)
+		if command == "" {
+			command = "/bin/sh"
+			if interpreter, ok := defaultInterpreters[language]; ok {
+				command = interpreter
+			}
+		}
+	}
+
+	if strings.Contains(command, "/") {
+		if info, err := os.Stat(command); err != nil || info.IsDir() {
+			return fmt.Errorf("interpreter %s for hook '%s' does not exist", command, hook.ID)
+		}
+		return nil
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return fmt.Errorf("interpreter '%s' for hook '%s' not found on PATH", command, hook.ID)
+	}
+	return nil
+}
+
+// hookCommand builds the command running an installed hook. The interpreter
+// named by the hook's shebang is invoked explicitly, so hooks keep working
+// when they lost their executable bit or live on a noexec mount.
+func hookCommand(hookPath string, args ...string) *exec.Cmd {
+	file, err := os.Open(hookPath)
+	if err != nil {
+		return exec.Command(hookPath, args...)
+	}
+	defer file.Close()
+
+	head := make([]byte, 256)
+	n, _ := file.Read(head)
+	firstLine, _, _ := strings.Cut(string(head[:n]), "\n")
+	if !strings.HasPrefix(firstLine, "#!") {
+		return exec.Command("/bin/sh", append([]string{hookPath}, args...)...)
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimRight(firstLine, "\r"), "#!"))
+	if len(fields) == 0 {
+		return exec.Command(hookPath, args...)
+	}
+	cmdArgs := append(fields[1:], hookPath)
+	return exec.Command(fields[0], append(cmdArgs, args...)...)
+}
+
+// shebangInterpreter returns the program a shebang line runs, looking through
+// /usr/bin/env to the command it invokes.
+func shebangInterpreter(shebang string) string {
+	fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	if path.Base(fields[0]) == "env" {
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				return field
+			}
+		}
+	}
+	return fields[0]
+}
+
+// hasAsset reports whether name is one of the hook's asset files, or a
+// directory containing some.
+func hasAsset(hook Hook, name string) bool {
+	name = path.Clean(name)
+	for _, asset := range hook.assetFiles {
+		if asset.Path == name || strings.HasPrefix(asset.Path, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote quotes s for safe use as a single sh word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import "testing"
+
+func TestBuildHookContent(t *testing.T) {
+	tests := []struct {
+		name string
+		hook Hook
+		want string
+	}{
+		{
+			name: "shell",
+			hook: Hook{Description: "Lints", Script: "echo lint"},
+			want: "#!/bin/sh\n# Lints\necho lint\n",
+		},
+		{
+			name: "python",
+			hook: Hook{Description: "Lints", Language: languagePython, Script: "print('lint')"},
+			want: "#!/usr/bin/env python3\n# Lints\nprint('lint')\n",
+		},
+		{
+			name: "node has no hash comments",
+			hook: Hook{Description: "Lints", Language: languageNode, Script: "console.log('lint')"},
+			want: "#!/usr/bin/env node\nconsole.log('lint')\n",
+		},
+		{
+			name: "own shebang",
+			hook: Hook{Description: "Lints", Language: languageExecutable, Script: "#!/usr/bin/env bash\necho lint"},
+			want: "#!/usr/bin/env bash\n# Lints\necho lint\n",
+		},
+		{
+			name: "own shebang without hash comments",
+			hook: Hook{Description: "Lints", Language: languageExecutable, Script: "#!/usr/bin/env -S deno run\nconsole.log('lint')"},
+			want: "#!/usr/bin/env -S deno run\nconsole.log('lint')\n",
+		},
+		{
+			name: "versioned python",
+			hook: Hook{Description: "Lints", Script: "#!/usr/bin/python3.12\nprint('lint')"},
+			want: "#!/usr/bin/python3.12\n# Lints\nprint('lint')\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, extra := buildHookContent(tt.hook, "/store")
+			if got != tt.want || len(extra) != 0 {
+				t.Errorf("buildHookContent() = %q, %v, want %q", got, extra, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildHookContentGoRun(t *testing.T) {
+	got, extra := buildHookContent(Hook{Description: "Lints", Language: languageGoRun, Script: "package main"}, "/store")
+	if want := "#!/bin/sh\n# Lints\nexec go run '/store/main.go' \"$@\"\n"; got != want {
+		t.Errorf("buildHookContent() = %q, want %q", got, want)
+	}
+	if len(extra) != 1 || extra[0].Path != goScriptAsset || string(extra[0].Data) != "package main" {
+		t.Errorf("buildHookContent() assets = %+v, want the script as %s", extra, goScriptAsset)
+	}
+}
@@ -1,16 +1,27 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
 	"github.com/vjayajv/omnihook/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 var (
-	listAll  bool
-	hookType string
+	listAll      bool
+	hookType     string
+	listEnabled  bool
+	listDisabled bool
+	listSource   string
+	listTags     []string
+	listOutput   string
 )
 
 // Valid Git hook types
@@ -33,7 +44,11 @@ var validHookTypes = []string{
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List installed Git hooks",
-	Long:  "List installed Git hooks. Use --all to list all hooks or --type to list specific hook type.",
+	Long: `List installed Git hooks with their name, types, status, source and the
+result of their last run. A hook installed for several types is listed once,
+with its status for each type when they differ. Hooks of every type are listed
+unless --type narrows them down, and --enabled, --disabled, --source and --tag
+filter the list further.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		hooksDir := utils.ExpandPath(viper.GetString("omni_hooks_dir"))
 
@@ -42,13 +57,8 @@ var listCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if listAll || hookType == "all" {
-			listAllHooks(hooksDir)
-			return
-		}
-
 		// Validate hook type if specified
-		if hookType != "" && !isValidHookType(hookType) {
+		if hookType != "" && hookType != "all" && !isValidHookType(hookType) {
 			fmt.Printf("Invalid hook type: %s\n", hookType)
 			fmt.Println("Valid hook types:")
 			for _, t := range validHookTypes {
@@ -57,75 +67,191 @@ var listCmd = &cobra.Command{
 			return
 		}
 
-		// List specific hook type
-		if hookType != "" {
-			listHookType(hooksDir, hookType)
-			return
+		if listOutput != "table" && listOutput != "json" && listOutput != "yaml" {
+			fmt.Printf("Invalid output format: %s (expected table, json or yaml)\n", listOutput)
+			os.Exit(1)
 		}
 
-		cmd.Help()
+		entries, err := listHooks(hooksDir)
+		if err != nil {
+			fmt.Printf("Error reading hooks directory: %v\n", err)
+			os.Exit(1)
+		}
 
+		switch listOutput {
+		case "json":
+			if entries == nil {
+				entries = []hookListEntry{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.Encode(entries)
+		case "yaml":
+			if entries == nil {
+				entries = []hookListEntry{}
+			}
+			data, _ := yaml.Marshal(entries)
+			fmt.Print(string(data))
+		default:
+			printHookTable(entries)
+		}
 	},
 }
 
-func listHookType(hooksDir, hookType string) {
-	typeDir := fmt.Sprintf("%s/%s", hooksDir, hookType)
-	files, err := os.ReadDir(typeDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			fmt.Printf("No hooks installed for type: %s\n", hookType)
-			return
+// hookListEntry is an installed hook with the types it is installed for.
+type hookListEntry struct {
+	ID     string          `json:"id" yaml:"id"`
+	Name   string          `json:"name,omitempty" yaml:"name,omitempty"`
+	Types  []hookTypeState `json:"types" yaml:"types"`
+	Source string          `json:"source,omitempty" yaml:"source,omitempty"`
+	Alias  string          `json:"alias,omitempty" yaml:"alias,omitempty"`
+	Tags   []string        `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// LastResult is the most recent run of the hook for any of its types.
+	LastResult *RunResult `json:"lastResult,omitempty" yaml:"lastResult,omitempty"`
+}
+
+// listHooks collects the installed hooks matching the list flags, ordered by
+// ID. A hook installed for several types is listed once, with only the types
+// that match the flags.
+func listHooks(hooksDir string) ([]hookListEntry, error) {
+	hookTypes := []string{hookType}
+	if listAll || hookType == "" || hookType == "all" {
+		types, err := listHookTypes(hooksDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
 		}
-		fmt.Printf("Error accessing hooks directory: %v\n", err)
-		os.Exit(1)
+		hookTypes = types
 	}
 
-	if len(files) == 0 {
-		fmt.Printf("No hooks installed for type: %s\n", hookType)
-		return
+	cache, err := readCache()
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Printf("Installed %s hooks:\n", hookType)
-	for _, file := range files {
-		if !file.IsDir() {
-			fmt.Println("  -", file.Name())
+	var entries []hookListEntry
+	byID := make(map[string]int)
+	for _, t := range hookTypes {
+		files, err := listHookFiles(hooksDir, t)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			record, _ := cache.findHook(file.HookType, file.ID)
+			i, ok := byID[file.ID]
+			if !ok {
+				i = len(entries)
+				byID[file.ID] = i
+				entries = append(entries, hookListEntry{
+					ID:     file.ID,
+					Name:   record.Name,
+					Source: record.Source,
+					Alias:  cache.aliasForSource(record.Source),
+					Tags:   record.Tags,
+				})
+			}
+			entry := &entries[i]
+			entry.Types = append(entry.Types, hookTypeState{HookType: file.HookType, Enabled: !file.Disabled})
+			if record.LastResult != nil && (entry.LastResult == nil || record.LastResult.At.After(entry.LastResult.At)) {
+				entry.LastResult = record.LastResult
+			}
 		}
 	}
-}
 
+	var matching []hookListEntry
+	for _, entry := range entries {
+		if entry = filterListEntry(entry); len(entry.Types) > 0 {
+			sort.Slice(entry.Types, func(i, j int) bool { return entry.Types[i].HookType < entry.Types[j].HookType })
+			matching = append(matching, entry)
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool { return matching[i].ID < matching[j].ID })
+	return matching, nil
+}
 
-func listAllHooks(hooksDir string) {
-	dirs, err := os.ReadDir(hooksDir)
-	if err != nil {
-		fmt.Printf("Error reading hooks directory: %v\n", err)
-		os.Exit(1)
+// filterListEntry applies --enabled, --disabled, --source and --tag, dropping
+// the types of the entry that do not match; none are left when the hook is
+// filtered out. A source matches by alias or by any part of its URL or path,
+// and every given tag must be present.
+func filterListEntry(entry hookListEntry) hookListEntry {
+	if listSource != "" && entry.Alias != listSource && !strings.Contains(entry.Source, listSource) {
+		entry.Types = nil
+	}
+	for _, tag := range listTags {
+		if !slices.Contains(entry.Tags, tag) {
+			entry.Types = nil
+		}
+	}
+	if listEnabled || listDisabled {
+		var types []hookTypeState
+		for _, state := range entry.Types {
+			if state.Enabled == listEnabled {
+				types = append(types, state)
+			}
+		}
+		entry.Types = types
 	}
+	return entry
+}
 
-	if len(dirs) == 0 {
-		fmt.Println("No hooks installed.")
+func printHookTable(entries []hookListEntry) {
+	if len(entries) == 0 {
+		if listEnabled || listDisabled || listSource != "" || len(listTags) > 0 {
+			fmt.Println("No installed hooks match the given filters.")
+		} else {
+			fmt.Println("No hooks installed.")
+		}
 		return
 	}
 
-	
-	hookCount := 0
-	for _, dir := range dirs {
-		if dir.IsDir() {
-			files, err := os.ReadDir(fmt.Sprintf("%s/%s", hooksDir, dir.Name()))
-			if err != nil || len(files) == 0 {
-				continue
-			}
-			hookCount++
-			fmt.Println("└──", dir.Name())
-			for _, file := range files {
-				if !file.IsDir() {
-					fmt.Println("    ├──", file.Name())
-				}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTYPES\tSTATUS\tSOURCE\tLAST RESULT")
+	types := make(map[string]bool)
+	disabled := 0
+	for _, entry := range entries {
+		var names, states []string
+		enabled := 0
+		for _, state := range entry.Types {
+			types[state.HookType] = true
+			names = append(names, state.HookType)
+			status := "disabled"
+			if state.Enabled {
+				status = "enabled"
+				enabled++
 			}
+			states = append(states, state.HookType+": "+status)
+		}
+		// The status is only spelled out per type when the types differ
+		status := strings.Join(states, ", ")
+		switch enabled {
+		case len(entry.Types):
+			status = "enabled"
+		case 0:
+			status = "disabled"
+			disabled++
+		}
+		source := entry.Source
+		if entry.Alias != "" {
+			source = entry.Alias
 		}
+		lastResult := "-"
+		if entry.LastResult != nil {
+			lastResult = fmt.Sprintf("%s %s", entry.LastResult.Status, entry.LastResult.At.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", entry.ID, valueOrDash(entry.Name), strings.Join(names, ", "), status, valueOrDash(source), lastResult)
 	}
-	if hookCount == 0 {
-		fmt.Println("No hooks installed.")
+	w.Flush()
+
+	fmt.Printf("\n%d hook(s) across %d hook type(s), %d disabled\n", len(entries), len(types), disabled)
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
 	}
+	return value
 }
 
 func isValidHookType(hookType string) bool {
@@ -139,6 +265,12 @@ func isValidHookType(hookType string) bool {
 
 func init() {
 	rootCmd.AddCommand(listCmd)
-	listCmd.Flags().BoolVar(&listAll, "all", false, "List all installed hooks")
+	listCmd.Flags().BoolVar(&listAll, "all", false, "List hooks of every type (the default)")
 	listCmd.Flags().StringVar(&hookType, "type", "", "List hooks of specific type")
+	listCmd.Flags().BoolVar(&listEnabled, "enabled", false, "Only list enabled hooks")
+	listCmd.Flags().BoolVar(&listDisabled, "disabled", false, "Only list disabled hooks")
+	listCmd.Flags().StringVar(&listSource, "source", "", "Only list hooks from a source, given by alias or part of its URL or path")
+	listCmd.Flags().StringSliceVar(&listTags, "tag", nil, "Only list hooks with this tag (repeatable)")
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "table", "Output format: table, json or yaml")
+	listCmd.MarkFlagsMutuallyExclusive("enabled", "disabled")
 }
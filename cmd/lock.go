@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vjayajv/omnihook/utils"
+	"gopkg.in/yaml.v3"
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Pin the installed hook sources in a lockfile for omnihook ci",
+	Long: `Write the sources of the installed hooks to .omnihook.lock at the root of the
+current repository, pinned to the commit or checksum that is installed, so
+omnihook ci runs exactly the hooks installed locally.`,
+	RunE: writeLockfile,
+}
+
+func init() {
+	lockCmd.Flags().String("output", "", "Write the lockfile here instead of the repository root")
+	rootCmd.AddCommand(lockCmd)
+}
+
+func writeLockfile(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	repoRoot, err := utils.GitRepoRoot()
+	if err != nil && output == "" {
+		return err
+	}
+	if output == "" {
+		output = filepath.Join(repoRoot, repoLockFile)
+	}
+
+	cache, err := readCache()
+	if err != nil {
+		return err
+	}
+
+	// Sources installed from a single file are not tracked for update, but
+	// their hooks still record where they came from
+	sources := append([]string{}, cache.Sources...)
+	for _, hook := range cache.Hooks {
+		if !slices.Contains(sources, hook.Source) {
+			sources = append(sources, hook.Source)
+		}
+	}
+	if len(sources) == 0 {
+		return errors.New("no hooks installed")
+	}
+
+	var list sourceList
+	for _, source := range sources {
+		var ref string
+		for _, hook := range cache.Hooks {
+			if hook.Source == source && hook.Ref != "" {
+				ref = hook.Ref
+				break
+			}
+		}
+		opts := sourceOptions(source, cache.Checksums[source])
+		if ref != "" {
+			// Only git sources have a ref, even when cloned from a local path
+			opts = installOptions{URL: source}
+		}
+		spec := sourceSpec{URL: opts.URL, Alias: cache.aliasForSource(source), Ref: ref, SHA256: opts.Checksum}
+
+		// Local paths only work in CI when they are part of the repository
+		local := opts.File + opts.Dir
+		if local != "" {
+			rel := local
+			if repoRoot != "" {
+				if r, err := filepath.Rel(repoRoot, local); err == nil && !strings.HasPrefix(r, "..") {
+					rel = filepath.ToSlash(r)
+				}
+			}
+			if filepath.IsAbs(rel) {
+				fmt.Printf("Warning: %s is outside the repository and will not be found in CI\n", local)
+			}
+			if opts.Dir != "" {
+				spec.Dir = rel
+			} else {
+				spec.File = rel
+			}
+		}
+		list.Sources = append(list.Sources, spec)
+	}
+
+	data, err := yaml.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to serialize lockfile: %w", err)
+	}
+	header := "# Generated by omnihook lock. Hook sources installed by omnihook ci.\n"
+	if err := os.WriteFile(output, append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	fmt.Printf("Pinned %d source(s) in %s\n", len(list.Sources), output)
+	return nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/jwalton/gchalk"
+	"github.com/vjayajv/omnihook/utils"
+)
+
+// fileSnapshot records the content of the files hooks run against, so that
+// the files hooks modify, such as formatters and fixers, can be found once
+// they have finished.
+type fileSnapshot struct {
+	repoRoot string
+	// checksums maps each file to the checksum of its content, or "" when
+	// it could not be read.
+	checksums map[string]string
+}
+
+func snapshotFiles(repoRoot string, files []string) fileSnapshot {
+	snapshot := fileSnapshot{repoRoot: repoRoot, checksums: make(map[string]string, len(files))}
+	for _, file := range files {
+		snapshot.checksums[file] = fileChecksum(filepath.Join(repoRoot, filepath.FromSlash(file)))
+	}
+	return snapshot
+}
+
+// modified returns the files whose content changed since the snapshot.
+func (s fileSnapshot) modified() []string {
+	var modified []string
+	for file, checksum := range s.checksums {
+		if fileChecksum(filepath.Join(s.repoRoot, filepath.FromSlash(file))) != checksum {
+			modified = append(modified, file)
+		}
+	}
+	sort.Strings(modified)
+	return modified
+}
+
+func fileChecksum(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return contentChecksum(data)
+}
+
+// handleModifiedFiles reports files hooks modified. With restage, those that
+// were fully staged before the hooks ran are staged again so the commit
+// includes the changes; the rest would sweep unrelated changes into the
+// commit, so they are left for the user to review. It returns whether any
+// modified files are left unstaged, which fails the run.
+func handleModifiedFiles(repoRoot string, modified, unstagedBefore []string, restage bool) (bool, error) {
+	var restaged, unstaged []string
+	for _, file := range modified {
+		if restage && !slices.Contains(unstagedBefore, file) {
+			restaged = append(restaged, file)
+		} else {
+			unstaged = append(unstaged, file)
+		}
+	}
+	if len(restaged) > 0 {
+		if err := utils.GitAdd(repoRoot, restaged); err != nil {
+			return false, fmt.Errorf("failed to stage files modified by hooks: %w", err)
+		}
+		fmt.Printf("\n🔧 %s\n", gchalk.Bold("Hooks modified these files, which were staged again:"))
+		for _, file := range restaged {
+			fmt.Printf("   %s\n", file)
+		}
+	}
+	if len(unstaged) > 0 {
+		fmt.Printf("\n🔧 %s\n", gchalk.Bold("Hooks modified these files; review the changes and stage them:"))
+		for _, file := range unstaged {
+			fmt.Printf("   %s\n", gchalk.Yellow(file))
+		}
+	}
+	return len(unstaged) > 0, nil
+}
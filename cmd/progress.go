@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/lianggaoqiang/progress"
+)
+
+// hookProgress shows a progress bar per hook while hooks are installed or
+// run. A quiet hookProgress draws nothing, for output that is not a terminal.
+type hookProgress struct {
+	p    *progress.Progress
+	bars map[string]*progress.DefaultBar
+}
+
+func newHookProgress(quiet bool) *hookProgress {
+	hp := &hookProgress{bars: make(map[string]*progress.DefaultBar)}
+	if !quiet {
+		hp.p = progress.Start()
+	}
+	return hp
+}
+
+// add creates the bar for key, labelled with label.
+func (hp *hookProgress) add(key, label string) {
+	if hp.p == nil {
+		return
+	}
+	hp.bars[key] = progress.NewBar().Custom(progress.BarSetting{
+		Total:         15,
+		StartText:     label,
+		EndText:       " ✅",
+		NotPassedText: progress.BlackText("▇"),
+		PassedText:    progress.WhiteText("▇"),
+	})
+	hp.p.AddBar(hp.bars[key])
+}
+
+// show reveals the bar for key once its hook has started.
+func (hp *hookProgress) show(key string) {
+	if bar, ok := hp.bars[key]; ok {
+		bar.Show()
+	}
+}
+
+// finish animates the bar for key to its end, marked as passed or failed.
+func (hp *hookProgress) finish(key string, ok bool) {
+	bar, found := hp.bars[key]
+	if !found {
+		return
+	}
+	endText := " ✅"
+	if !ok {
+		endText = " ❌"
+	}
+	for i := 0; i < 100; i += 20 {
+		bar.Show()
+		bar.Inc()
+		bar.Add(1.4)
+		bar.Setting.EndText = endText
+		bar.Percent(float64(i))
+		bar.Hide()
+		time.Sleep(100 * time.Millisecond)
+	}
+	bar.Show()
+	bar.Percent(100)
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vjayajv/omnihook/checks"
+)
+
+// JUnit XML, in the shape CI systems commonly accept: a suite per hook type
+// and a test case per hook.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnitReport(path string, results []hookResult) error {
+	report := junitTestSuites{Name: "omnihook"}
+	suites := make(map[string]*junitTestSuite)
+	seconds := make(map[string]float64)
+	var types []string
+	var total float64
+	for _, result := range results {
+		suite, ok := suites[result.HookType]
+		if !ok {
+			suite = &junitTestSuite{Name: result.HookType}
+			suites[result.HookType] = suite
+			types = append(types, result.HookType)
+		}
+
+		testCase := junitTestCase{
+			Name:      result.ID,
+			ClassName: "omnihook." + result.HookType,
+			Time:      fmt.Sprintf("%.3f", result.Duration.Seconds()),
+			SystemOut: result.Output,
+		}
+		if !result.Passed {
+			testCase.Failure = &junitFailure{Message: fmt.Sprintf("hook %s failed", result.ID), Text: result.Output}
+			// A test case has a single location, so it points at the first
+			// problem; the failure text lists every one
+			for _, finding := range result.Findings {
+				if finding.IsError() {
+					testCase.File, testCase.Line = finding.File, finding.Line
+					testCase.Failure.Message = finding.String()
+					break
+				}
+			}
+			suite.Failures++
+			report.Failures++
+		}
+		if result.Skipped {
+			testCase.Skipped = &junitSkipped{}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+		report.Tests++
+		seconds[result.HookType] += result.Duration.Seconds()
+		total += result.Duration.Seconds()
+	}
+
+	sort.Strings(types)
+	for _, hookType := range types {
+		suite := suites[hookType]
+		suite.Time = fmt.Sprintf("%.3f", seconds[hookType])
+		report.Suites = append(report.Suites, *suite)
+	}
+	report.Time = fmt.Sprintf("%.3f", total)
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to generate JUnit report: %w", err)
+	}
+	data = append([]byte(xml.Header), append(data, '\n')...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+// SARIF 2.1.0, reduced to what is needed to report failed hooks as results
+// of a single omnihook run.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+func writeSARIFReport(path string, results []hookResult) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "omnihook",
+			InformationURI: "https://github.com/vjayajv/omnihook",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]bool)
+	for _, result := range results {
+		if !rules[result.ID] {
+			rules[result.ID] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               result.ID,
+				ShortDescription: sarifMessage{Text: fmt.Sprintf("omnihook hook %s", result.ID)},
+			})
+		}
+		// Findings of builtin checks are reported one by one at their
+		// location, warnings included
+		if len(result.Findings) > 0 {
+			for _, finding := range result.Findings {
+				run.Results = append(run.Results, sarifFindingResult(result.ID, finding))
+			}
+			continue
+		}
+		if result.Passed {
+			continue
+		}
+		message := strings.TrimSpace(result.Output)
+		if message == "" {
+			message = fmt.Sprintf("%s hook %s failed", result.HookType, result.ID)
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  result.ID,
+			Level:   "error",
+			Message: sarifMessage{Text: message},
+		})
+	}
+
+	report := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to generate SARIF report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write SARIF report: %w", err)
+	}
+	return nil
+}
+
+// sarifFindingResult reports a finding of a builtin check as a result of the
+// hook that ran it.
+func sarifFindingResult(hookID string, finding checks.Finding) sarifResult {
+	text := finding.Message
+	if finding.Rule != "" {
+		text += fmt.Sprintf(" [%s]", finding.Rule)
+	}
+	result := sarifResult{RuleID: hookID, Level: "error", Message: sarifMessage{Text: text}}
+	if !finding.IsError() {
+		result.Level = "warning"
+	}
+	if finding.File != "" {
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(finding.File)}}
+		if finding.Line > 0 {
+			location.Region = &sarifRegion{StartLine: finding.Line, StartColumn: finding.Column}
+		}
+		result.Locations = []sarifLocation{{PhysicalLocation: location}}
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vjayajv/omnihook/checks"
+)
+
+// reportResults are a builtin hook with findings, a failed script hook and
+// a passing one.
+var reportResults = []hookResult{
+	{
+		ID:       "leftovers",
+		HookType: "pre-commit",
+		Output:   "app.py:3:5: debug statement\n",
+		Findings: []checks.Finding{
+			{File: "notes.md", Rule: "style", Message: "heading", Severity: checks.SeverityWarning},
+			{File: filepath.Join("src", "app.py"), Line: 3, Column: 5, Rule: "debug-statement", Message: "debug statement"},
+			{File: "a.txt", Line: 7, Message: "conflict"},
+		},
+		Duration: 1500 * time.Millisecond,
+	},
+	{ID: "lint", HookType: "pre-commit", Output: "lint failed\n", ExitCode: 1, Duration: time.Second},
+	{ID: "fmt", HookType: "pre-push", Passed: true},
+}
+
+func TestWriteSARIFReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.sarif")
+	if err := writeSARIFReport(path, reportResults); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report sarifLog
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []sarifResult{
+		{
+			RuleID:    "leftovers",
+			Level:     "warning",
+			Message:   sarifMessage{Text: "heading [style]"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "notes.md"}}}},
+		},
+		{
+			RuleID:  "leftovers",
+			Level:   "error",
+			Message: sarifMessage{Text: "debug statement [debug-statement]"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "src/app.py"},
+				Region:           &sarifRegion{StartLine: 3, StartColumn: 5},
+			}}},
+		},
+		{
+			RuleID:  "leftovers",
+			Level:   "error",
+			Message: sarifMessage{Text: "conflict"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "a.txt"},
+				Region:           &sarifRegion{StartLine: 7},
+			}}},
+		},
+		{RuleID: "lint", Level: "error", Message: sarifMessage{Text: "lint failed"}},
+	}
+	if len(report.Runs) != 1 {
+		t.Fatalf("report has %d runs, want 1", len(report.Runs))
+	}
+	if got := report.Runs[0].Results; !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %+v, want %+v", got, want)
+	}
+	if rules := report.Runs[0].Tool.Driver.Rules; len(rules) != 3 {
+		t.Errorf("rules = %+v, want one per hook", rules)
+	}
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.xml")
+	if err := writeJUnitReport(path, reportResults); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report junitTestSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Tests != 3 || report.Failures != 2 || len(report.Suites) != 2 {
+		t.Fatalf("report has %d tests, %d failures and %d suites, want 3, 2 and 2", report.Tests, report.Failures, len(report.Suites))
+	}
+	cases := report.Suites[0].Cases
+	if report.Suites[0].Name != "pre-commit" || len(cases) != 2 {
+		t.Fatalf("first suite = %+v, want the two pre-commit hooks", report.Suites[0])
+	}
+
+	// The location is that of the first error, skipping warnings
+	leftovers := cases[0]
+	if leftovers.File != filepath.Join("src", "app.py") || leftovers.Line != 3 {
+		t.Errorf("leftovers is at %s:%d, want src/app.py:3", leftovers.File, leftovers.Line)
+	}
+	if leftovers.Failure == nil || leftovers.Failure.Message != "src/app.py:3:5: debug statement [debug-statement]" {
+		t.Errorf("leftovers failure = %+v, want the first error", leftovers.Failure)
+	}
+
+	lint := cases[1]
+	if lint.File != "" || lint.Line != 0 {
+		t.Errorf("lint is at %s:%d, want no location", lint.File, lint.Line)
+	}
+	if lint.Failure == nil || lint.Failure.Message != "hook lint failed" || lint.Failure.Text != "lint failed\n" {
+		t.Errorf("lint failure = %+v, want its output", lint.Failure)
+	}
+
+	if fmtCase := report.Suites[1].Cases[0]; fmtCase.Failure != nil {
+		t.Errorf("fmt failure = %+v, want none", fmtCase.Failure)
+	}
+}
@@ -1,172 +1,552 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
-	"os"
 
 	"github.com/spf13/viper"
 	"github.com/spf13/cobra"
-	"github.com/lianggaoqiang/progress"
 	"github.com/jwalton/gchalk"
+	"github.com/vjayajv/omnihook/checks"
+	"github.com/vjayajv/omnihook/utils"
 )
 
 var runCmd = &cobra.Command{
-	Use:   "run --all or --type <hook_type>",
+	Use:   "run --all or --type <hook_type> or --hook <[alias/]hook_id> [--files <file>... | --all-files | --from-ref <ref>]",
 	Short: "Run all installed hooks in parallel",
-	RunE:  runHooks,
+	Long: `Run installed hooks in parallel, as git does when it triggers them.
+
+By default hooks that take filenames receive the staged files. --files,
+--all-files and --from-ref/--to-ref run them against another set of files
+instead, such as the whole repository when adopting a new hook or the files
+changed on a branch in CI.`,
+	RunE: runHooks,
 }
 
 func init() {
-	runCmd.Flags().String("commit-msg", "", "Commit message passed from git commit")
-	runCmd.Flags().Bool("all", false, "Run all installed hooks")
-	runCmd.Flags().String("type", "", "Run all installed hooks of a specific type")
+	addRunFlags(runCmd)
 	rootCmd.AddCommand(runCmd)
 }
 
+// addRunFlags defines the flags of the run command on cmd.
+func addRunFlags(cmd *cobra.Command) {
+	cmd.Flags().String("commit-msg", "", "Commit message passed from git commit")
+	cmd.Flags().String("commit-msg-file", "", "File holding the commit message, as git passes it to commit-msg hooks")
+	cmd.Flags().Bool("all", false, "Run all installed hooks")
+	cmd.Flags().String("type", "", "Run all installed hooks of a specific type")
+	cmd.Flags().String("hook", "", "Run a single hook, of every type it is installed for unless --type is given")
+	cmd.Flags().StringSlice("files", nil, "Run against these files instead of the staged ones; further arguments are taken as files too")
+	cmd.Flags().Bool("all-files", false, "Run against every file tracked in the repository")
+	cmd.Flags().String("from-ref", "", "Run against the files changed since this ref")
+	cmd.Flags().String("to-ref", "HEAD", "End of the range started by --from-ref")
+	cmd.Flags().StringSlice("skip", nil, "Skip these hooks, in addition to those listed in OMNIHOOK_SKIP")
+	cmd.Flags().String("skip-reason", "", "Why hooks are skipped, recorded in the audit log (default: OMNIHOOK_SKIP_REASON)")
+	cmd.Flags().String("remote", "", "Remote being pushed to, passed from git push; the pushed refs are read from stdin")
+	cmd.Flags().String("remote-url", "", "URL of the remote being pushed to, passed from git push")
+	cmd.Flags().StringArray("hook-arg", nil, "Argument git passed to the hook, handed on to each hook; may be repeated")
+	cmd.MarkFlagsMutuallyExclusive("files", "all-files", "from-ref")
+	cmd.MarkFlagsMutuallyExclusive("all", "hook")
+	cmd.MarkFlagsMutuallyExclusive("commit-msg", "commit-msg-file")
+}
+
 func runHooks(cmd *cobra.Command, args []string) error {
 	hookType, _ := cmd.Flags().GetString("type")
 	all, _ := cmd.Flags().GetBool("all")
-	if !all && hookType == "" {
-		return errors.New("must specify either --all or --type")
+	hookID, _ := cmd.Flags().GetString("hook")
+	if !all && hookType == "" && hookID == "" {
+		return errors.New("must specify either --all, --type or --hook")
 	}
 	commitMsg, _ := cmd.Flags().GetString("commit-msg")
+	commitMsgFile, _ := cmd.Flags().GetString("commit-msg-file")
+	if commitMsgFile != "" {
+		data, err := os.ReadFile(commitMsgFile)
+		if err != nil {
+			return fmt.Errorf("failed to read commit message: %w", err)
+		}
+		commitMsg = string(data)
+	}
 
 	hooksDir := viper.GetString("omni_hooks_dir")
 	if hooksDir == "" {
 		return errors.New("hooks directory not set. Run 'omnihook configure' first")
 	}
 
-	var hookFiles []string
-	var err error
-	
-	if all {
-		// Get all hooks from all subdirectories
-		hookFiles, err = filepath.Glob(filepath.Join(hooksDir, "**/*"))
-	} else {
-		// Get hooks only from specific type subdirectory
-		hookFiles, err = filepath.Glob(filepath.Join(hooksDir, hookType, "*"))
+	// Hooks run from the repository root unless configured otherwise; outside
+	// a repository they run in the current directory.
+	repoRoot, _ := utils.GitRepoRoot()
+	files, explicitFiles, err := runFiles(cmd, args, repoRoot)
+	if err != nil {
+		return err
 	}
-	
+
+	var hookTypes []string
+	if !all && hookType != "" {
+		hookTypes = []string{hookType}
+	}
+	activeHooks, err := selectHooks(hooksDir, hookTypes, hookID)
 	if err != nil {
-		return fmt.Errorf("failed to list hooks: %w", err)
+		return err
+	}
+	if len(activeHooks) == 0 {
+		return nil
+	}
+
+	skip, _ := cmd.Flags().GetStringSlice("skip")
+	skipReason, _ := cmd.Flags().GetString("skip-reason")
+	if skipReason == "" {
+		skipReason = os.Getenv("OMNIHOOK_SKIP_REASON")
 	}
 
-	// Filter out .disabled hooks and directories
-	var activeHooks []string
-	for _, hookPath := range hookFiles {
-		if strings.HasSuffix(hookPath, ".disabled") {
-			continue // Skip disabled hooks
+	// git describes what is being pushed on the stdin of pre-push hooks
+	hookArgs, _ := cmd.Flags().GetStringArray("hook-arg")
+	remote, _ := cmd.Flags().GetString("remote")
+	remoteURL, _ := cmd.Flags().GetString("remote-url")
+	var pushInput []byte
+	if remote != "" {
+		if pushInput, err = io.ReadAll(os.Stdin); err != nil {
+			return fmt.Errorf("failed to read pushed refs: %w", err)
 		}
-		// Skip if it's a directory
-		if info, err := os.Stat(hookPath); err == nil && info.IsDir() {
-			continue
+	}
+
+	// Formatters and fixers may modify the files they run against, and
+	// the commit would leave their changes out
+	var snapshot fileSnapshot
+	var unstagedBefore []string
+	restage := false
+	watch := repoRoot != "" && len(files) > 0 && (explicitFiles || slices.ContainsFunc(activeHooks, func(hook installedHookFile) bool {
+		return hook.HookType == "pre-commit"
+	}))
+	if watch {
+		snapshot = snapshotFiles(repoRoot, files)
+		if !explicitFiles {
+			if restage, err = loadRestage(repoRoot); err != nil {
+				return err
+			}
+		}
+		if restage {
+			if unstagedBefore, err = utils.GitUnstagedFiles(); err != nil {
+				return err
+			}
 		}
-		activeHooks = append(activeHooks, hookPath)
 	}
 
-	if len(activeHooks) == 0 {
-		return nil
+	results, err := executeHooks(runRequest{
+		hooksDir:      hooksDir,
+		hooks:         activeHooks,
+		repoRoot:      repoRoot,
+		commitMsg:     commitMsg,
+		commitMsgFile: commitMsgFile,
+		files:         files,
+		explicitFiles: explicitFiles,
+		skip:          append(skipList(os.Getenv("OMNIHOOK_SKIP")), skip...),
+		skipReason:    skipReason,
+		hookArgs:      hookArgs,
+		remote:        remote,
+		remoteURL:     remoteURL,
+		pushInput:     pushInput,
+	})
+	if err != nil {
+		return err
 	}
 
-	// Rest of the code remains the same...
-	var wg sync.WaitGroup
-	type HookResult struct {
-		name     string
-		status   string
-		errorMsg string
+	fmt.Println()
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			fmt.Printf("⏭️  %s skipped\n", gchalk.Bold(result.ID))
+		case result.SkipRefused:
+			fmt.Printf("🔒 %s is required and cannot be skipped\n", gchalk.Bold(result.ID))
+		}
+	}
+	failureCount := 0
+	for _, result := range results {
+		if !result.Passed {
+			fmt.Printf("\n🚧 %s check failed:\n%s\n\n", gchalk.Bold(result.ID), gchalk.Red(result.Output))
+			failureCount++
+		} else if len(result.Findings) > 0 {
+			// Warnings never fail a hook but are still worth reading
+			fmt.Printf("\n⚠️  %s reported warnings:\n%s\n\n", gchalk.Bold(result.ID), gchalk.Yellow(result.Output))
+		}
+	}
+
+	if watch {
+		left, err := handleModifiedFiles(repoRoot, snapshot.modified(), unstagedBefore, restage)
+		if err != nil {
+			return err
+		}
+		if left {
+			failureCount++
+		}
+	}
+
+	if failureCount > 0 {
+		cmd.SilenceUsage = true
+		return errors.New("one or more pre-commit checks failed")
+	}
+
+	return nil
+}
+
+// selectHooks returns the enabled hooks of hookTypes, or of every type when
+// none are given. With a hookID only that hook is selected, and it is an
+// error for it to be missing or disabled.
+func selectHooks(hooksDir string, hookTypes []string, hookID string) ([]installedHookFile, error) {
+	if hookID != "" {
+		if len(hookTypes) == 0 {
+			hookTypes = []string{""}
+		}
+		var targets []installedHookFile
+		for _, t := range hookTypes {
+			found, err := hookTargets(hooksDir, t, hookID)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, found...)
+		}
+		if len(targets) == 0 {
+			return nil, fmt.Errorf("hook '%s' does not exist", hookID)
+		}
+		var active []installedHookFile
+		for _, target := range targets {
+			if !target.Disabled {
+				active = append(active, target)
+			}
+		}
+		if len(active) == 0 {
+			return nil, fmt.Errorf("hook '%s' is disabled", hookID)
+		}
+		return active, nil
+	}
+
+	if len(hookTypes) == 0 {
+		// Get all hooks from all subdirectories
+		types, err := listHookTypes(hooksDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list hooks: %w", err)
+		}
+		hookTypes = types
+	}
+
+	// Filter out .disabled hooks
+	var active []installedHookFile
+	for _, t := range hookTypes {
+		files, err := listHookFiles(hooksDir, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list hooks: %w", err)
+		}
+		for _, file := range files {
+			if !file.Disabled {
+				active = append(active, file)
+			}
+		}
+	}
+	return active, nil
+}
+
+// runRequest is a set of hooks to run and the inputs they run with.
+type runRequest struct {
+	hooksDir  string
+	hooks     []installedHookFile
+	repoRoot  string
+	commitMsg string
+	// commitMsgFile is where git wrote commitMsg, if it came from a file.
+	commitMsgFile string
+	files     []string
+	// explicitFiles is set when files were chosen by the caller rather
+	// than being the staged files.
+	explicitFiles bool
+	// quiet runs without progress bars.
+	quiet bool
+	// skip lists hooks to skip unless they are required, by bare or
+	// namespaced ID.
+	skip       []string
+	skipReason string
+	// hookArgs are the arguments git passed to the hook.
+	hookArgs []string
+	// remote, remoteURL and pushInput are what git passes pre-push hooks:
+	// where it is pushing to and, on stdin, the refs it is pushing.
+	remote    string
+	remoteURL string
+	pushInput []byte
+}
+
+// skipList parses a comma separated list of hook IDs, as in OMNIHOOK_SKIP.
+func skipList(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// hookResult is the outcome of running one hook.
+type hookResult struct {
+	ID       string
+	HookType string
+	Passed   bool
+	// Skipped hooks did not run; they count as passed.
+	Skipped bool
+	// SkipRefused is set when a required hook was asked to be skipped and
+	// ran anyway.
+	SkipRefused bool
+	ExitCode    int
+	Output      string
+	// Findings are what a builtin check reported, with their locations.
+	Findings []checks.Finding
+	Started  time.Time
+	Duration time.Duration
+}
+
+// executeHooks runs the requested hooks in parallel and returns their results
+// in the order the hooks were given. The outcome of each is also remembered
+// on its install record.
+func executeHooks(req runRequest) ([]hookResult, error) {
+	layers, err := loadOverrideLayers(req.repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := readCache()
+	if err != nil {
+		return nil, err
 	}
-	results := make(chan HookResult, len(activeHooks))
 
 	maxHookNameLength := 0
-	hookNames := make([]string, len(activeHooks))
-	for i, hookPath := range activeHooks {
-		hookNames[i] = filepath.Base(hookPath)
-		if len(hookNames[i]) > maxHookNameLength {
-			maxHookNameLength = len(hookNames[i])
+	for _, hook := range req.hooks {
+		if len(hook.ID) > maxHookNameLength {
+			maxHookNameLength = len(hook.ID)
 		}
 	}
 
-	p := progress.Start()
+	// Bars are keyed by path since hooks of different types may share an ID
+	bars := newHookProgress(req.quiet)
+	for _, hook := range req.hooks {
+		bars.add(hook.Path, fmt.Sprintf("🪝 %-*s ", maxHookNameLength, hook.ID))
+	}
+
+	var required []string
+	if len(req.skip) > 0 {
+		if required, err = loadRequiredHooks(req.repoRoot); err != nil {
+			return nil, err
+		}
+	}
+	var audit []auditEntry
+	runStarted := time.Now()
 
-	bars := make(map[string]*progress.DefaultBar)
-	for _, hookPath := range activeHooks {
-		hookName := filepath.Base(hookPath)
-		paddedHookName := fmt.Sprintf("🪝 %-*s ", maxHookNameLength, hookName)
-		bars[hookName] = progress.NewBar().Custom(progress.BarSetting{
-			Total:          15,
-			StartText:      paddedHookName,
-			EndText:        " ✅",
-			NotPassedText:  progress.BlackText("▇"),
-			PassedText:     progress.WhiteText("▇"),
-		})
-		p.AddBar(bars[hookName])
+	updates, err := checks.ParseRefUpdates(bytes.NewReader(req.pushInput))
+	if err != nil {
+		return nil, err
 	}
 
-	for _, hookPath := range activeHooks {
-		hookName := filepath.Base(hookPath)
+	var wg sync.WaitGroup
+	results := make([]hookResult, len(req.hooks))
+	for i, hook := range req.hooks {
+		record, _ := cache.findHook(hook.HookType, hook.ID)
+		settings := layers.apply(hook.ID, record.HookSettings)
+
+		refused := false
+		if slices.ContainsFunc(req.skip, func(pattern string) bool { return matchesHookID(pattern, hook.ID) }) {
+			refused = record.Required || slices.ContainsFunc(required, func(pattern string) bool { return matchesHookID(pattern, hook.ID) })
+			action := auditSkipped
+			if refused {
+				action = auditRefused
+			}
+			audit = append(audit, auditEntry{Time: time.Now().UTC(), Action: action, HookType: hook.HookType, Hook: hook.ID, Reason: req.skipReason})
+			if !refused {
+				results[i] = hookResult{ID: hook.ID, HookType: hook.HookType, Passed: true, Skipped: true, Started: time.Now()}
+				continue
+			}
+		}
+
 		wg.Add(1)
 
-		go func(hookName, hookPath string) {
+		go func() {
 			defer wg.Done()
 
-			bars[hookName].Show()
-			var cmdArgs []string
-			if hookType == "commit-msg" && commitMsg != "" {
-				cmdArgs = append(cmdArgs, commitMsg)
+			bars.show(hook.Path)
+			cmdArgs := append([]string{}, settings.Args...)
+			if hook.HookType == "commit-msg" && req.commitMsg != "" {
+				cmdArgs = append(cmdArgs, req.commitMsg)
 			}
-			cmd := exec.Command(hookPath, cmdArgs...)
-			output, err := cmd.CombinedOutput()
+			if hook.HookType == "pre-push" && req.remote != "" {
+				cmdArgs = append(cmdArgs, req.remote, req.remoteURL)
+			}
+			cmdArgs = append(cmdArgs, req.hookArgs...)
 
-			if err != nil {
-				for i := 0; i < 100; i += 20 {
-					bars[hookName].Show()
-					bars[hookName].Inc()
-					bars[hookName].Add(1.4)
-					bars[hookName].Setting.EndText = " ❌"
-					bars[hookName].Percent(float64(i))
-					bars[hookName].Hide()
-					time.Sleep(100 * time.Millisecond)
+			// Builtin checks run in-process and always see the files
+			if record.Builtin != "" {
+				ctx := &checks.Context{
+					HookType: hook.HookType,
+					RepoRoot: req.repoRoot,
+					Args:     cmdArgs,
+					Options:  settings.Options,
+				}
+				if req.explicitFiles || hook.HookType == "pre-commit" {
+					ctx.Files = req.files
+					ctx.Staged = !req.explicitFiles
+				}
+				if hook.HookType == "commit-msg" {
+					ctx.CommitMsg, ctx.CommitMsgFile = req.commitMsg, req.commitMsgFile
+				}
+				if hook.HookType == "pre-push" {
+					ctx.Remote, ctx.RemoteURL, ctx.Updates = req.remote, req.remoteURL, updates
 				}
-				results <- HookResult{hookName, "Failed", string(output)}
-			} else {
-				for i := 0; i < 100; i += 20 {
-					bars[hookName].Show()
-					bars[hookName].Inc()
-					bars[hookName].Add(1.4)
-					bars[hookName].Setting.EndText = " ✅"
-					bars[hookName].Percent(float64(i))
-					bars[hookName].Hide()
-					time.Sleep(100 * time.Millisecond)
+				started := time.Now()
+				findings, output, err := runBuiltin(record.Builtin, ctx)
+				exitCode := 0
+				if err != nil {
+					exitCode = 1
 				}
-				results <- HookResult{hookName, "Ok", ""}
+				results[i] = hookResult{
+					ID:          hook.ID,
+					HookType:    hook.HookType,
+					Passed:      err == nil,
+					SkipRefused: refused,
+					ExitCode:    exitCode,
+					Output:      output,
+					Findings:    findings,
+					Started:     started,
+					Duration:    time.Since(started),
+				}
+				bars.finish(hook.Path, err == nil)
+				return
 			}
-			bars[hookName].Show()
-			bars[hookName].Percent(100)
-		}(hookName, hookPath)
-	}
 
+			// Staged files only mean something to pre-commit hooks, but files
+			// asked for explicitly are passed whatever the type.
+			if settings.passFilenames() && (req.explicitFiles || hook.HookType == "pre-commit") {
+				cmdArgs = append(cmdArgs, req.files...)
+			}
+			cmd := hookCommand(hook.Path, cmdArgs...)
+			if hook.HookType == "pre-push" {
+				cmd.Stdin = bytes.NewReader(req.pushInput)
+			}
+			cmd.Dir = settings.workingDir(req.repoRoot)
+			cmd.Env = append(os.Environ(), "OMNIHOOK_HOOK_DIR="+hookStoreDir(req.hooksDir, hook.ID))
+			cmd.Env = append(cmd.Env, settings.environ()...)
+			started := time.Now()
+			output, err := cmd.CombinedOutput()
+			exitCode := 0
+			if err != nil {
+				// Hooks that could not be started have no exit code of their own
+				exitCode = -1
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					exitCode = exitErr.ExitCode()
+				}
+			}
+
+			results[i] = hookResult{
+				ID:          hook.ID,
+				HookType:    hook.HookType,
+				Passed:      err == nil,
+				SkipRefused: refused,
+				ExitCode:    exitCode,
+				Output:      string(output),
+				Started:     started,
+				Duration:    time.Since(started),
+			}
+			bars.finish(hook.Path, err == nil)
+		}()
+	}
 	wg.Wait()
-	close(results)
 
-	fmt.Println()
-	failureCount := 0
-	for result := range results {
-		if result.status == "Failed" {
-			fmt.Printf("\n🚧 %s check failed:\n%s\n\n", gchalk.Bold(result.name), gchalk.Red(result.errorMsg))
-			failureCount++
+	if len(audit) > 0 {
+		user, branch, commit := auditUser(), utils.GitCurrentBranch(), utils.GitHeadCommit()
+		for i := range audit {
+			audit[i].User, audit[i].Repo, audit[i].Branch, audit[i].Commit = user, req.repoRoot, branch, commit
+		}
+		if err := appendAuditEntries(audit); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning:", err)
 		}
 	}
-	
-	if failureCount > 0 {
-		cmd.SilenceUsage = true
-		return errors.New("one or more pre-commit checks failed")
+
+	if err := appendHistory(newHistoryRun(req.repoRoot, runStarted, results)); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
 	}
 
-	return nil
+	// Remember each hook's outcome for list. The cache is read again as hooks
+	// may have been installed while these ran, and a failure to save must not
+	// block the commit.
+	if cache, err := readCache(); err == nil {
+		for _, result := range results {
+			if result.Skipped {
+				continue
+			}
+			status := resultPassed
+			if !result.Passed {
+				status = resultFailed
+			}
+			cache.recordResult(result.HookType, result.ID, RunResult{
+				Status:   status,
+				At:       result.Started.UTC().Truncate(time.Second),
+				Duration: result.Duration.Round(time.Millisecond),
+			})
+		}
+		writeCache(cache)
+	}
+	return results, nil
+}
+
+// runFiles returns the files hooks run against, relative to the repository
+// root, and whether they were chosen on the command line rather than being the
+// staged files git commits.
+func runFiles(cmd *cobra.Command, args []string, repoRoot string) ([]string, bool, error) {
+	files, _ := cmd.Flags().GetStringSlice("files")
+	files = append(files, args...)
+	allFiles, _ := cmd.Flags().GetBool("all-files")
+	fromRef, _ := cmd.Flags().GetString("from-ref")
+	if cmd.Flags().Changed("to-ref") && fromRef == "" {
+		return nil, false, errors.New("--to-ref requires --from-ref")
+	}
+	// Further arguments add to --files, but would silently replace the
+	// files chosen with the other flags
+	if len(args) > 0 && (allFiles || fromRef != "") {
+		return nil, false, errors.New("file arguments cannot be combined with --all-files or --from-ref")
+	}
+
+	explicit := len(files) > 0 || allFiles || fromRef != ""
+	if explicit && repoRoot == "" {
+		return nil, false, errors.New("--files, --all-files and --from-ref must be run inside a git repository")
+	}
+
+	switch {
+	case len(files) > 0:
+		// Paths are given relative to the current directory but hooks run
+		// from the repository root
+		relFiles := make([]string, 0, len(files))
+		for _, file := range files {
+			abs, err := filepath.Abs(file)
+			if err != nil {
+				return nil, false, err
+			}
+			rel, err := filepath.Rel(repoRoot, abs)
+			if err != nil || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || rel == ".." {
+				return nil, false, fmt.Errorf("file '%s' is outside the repository", file)
+			}
+			relFiles = append(relFiles, filepath.ToSlash(rel))
+		}
+		return relFiles, true, nil
+	case allFiles:
+		tracked, err := utils.GitTrackedFiles()
+		return tracked, true, err
+	case fromRef != "":
+		toRef, _ := cmd.Flags().GetString("to-ref")
+		changed, err := utils.GitChangedFiles(fromRef, toRef)
+		return changed, true, err
+	case repoRoot != "":
+		staged, _ := utils.GitStagedFiles()
+		return staged, false, nil
+	}
+	return nil, false, nil
 }
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/vjayajv/omnihook/utils"
+)
+
+// newTestRepo creates a git repository holding files, with all of them
+// staged.
+func newTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	runGit(t, dir, "config", "user.name", "Test")
+	runGit(t, dir, "config", "commit.gpgsign", "false")
+	writeFiles(t, dir, files)
+	return dir
+}
+
+// writeFiles writes files into the repository at dir and stages them.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(files) > 0 {
+		runGit(t, dir, "add", "--all")
+	}
+}
+
+// runGit runs a git command in dir and returns its trimmed output.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := newTestRepo(t, map[string]string{"a.txt": "a\n", "b.txt": "b\n", "sub/c.txt": "c\n"})
+	runGit(t, dir, "commit", "--quiet", "-m", "first")
+	writeFiles(t, dir, map[string]string{"b.txt": "changed\n", "d.txt": "d\n"})
+	runGit(t, dir, "commit", "--quiet", "-m", "second")
+	writeFiles(t, dir, map[string]string{"e.txt": "staged\n"})
+	// Paths on the command line are relative to where omnihook runs
+	t.Chdir(filepath.Join(dir, "sub"))
+	repoRoot, err := utils.GitRepoRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		want         []string
+		wantExplicit bool
+		wantErr      string
+	}{
+		{name: "staged files", want: []string{"e.txt"}},
+		{name: "files", args: []string{"--files", "c.txt"}, want: []string{"sub/c.txt"}, wantExplicit: true},
+		{name: "files and arguments", args: []string{"--files", "c.txt", "../a.txt"}, want: []string{"sub/c.txt", "a.txt"}, wantExplicit: true},
+		{name: "arguments", args: []string{"c.txt"}, want: []string{"sub/c.txt"}, wantExplicit: true},
+		{name: "file outside the repository", args: []string{"--files", "../../outside.txt"}, wantErr: "outside the repository"},
+		{name: "all files", args: []string{"--all-files"}, want: []string{"a.txt", "b.txt", "d.txt", "e.txt", "sub/c.txt"}, wantExplicit: true},
+		{name: "from ref", args: []string{"--from-ref", "HEAD~1"}, want: []string{"b.txt", "d.txt"}, wantExplicit: true},
+		{name: "from ref to ref", args: []string{"--from-ref", "HEAD~1", "--to-ref", "HEAD~1"}, wantExplicit: true},
+		{name: "to ref alone", args: []string{"--to-ref", "HEAD"}, wantErr: "--to-ref requires --from-ref"},
+		{name: "files and all files", args: []string{"--files", "c.txt", "--all-files"}, wantErr: "none of the others can be"},
+		{name: "all files and from ref", args: []string{"--all-files", "--from-ref", "HEAD~1"}, wantErr: "none of the others can be"},
+		{name: "all files and arguments", args: []string{"--all-files", "c.txt"}, wantErr: "cannot be combined with --all-files or --from-ref"},
+		{name: "from ref and arguments", args: []string{"--from-ref", "HEAD~1", "c.txt"}, wantErr: "cannot be combined with --all-files or --from-ref"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Parsed the way Execute does, without loading the user's config
+			cmd := &cobra.Command{Use: "run"}
+			addRunFlags(cmd)
+			err := cmd.ParseFlags(tt.args)
+			if err == nil {
+				err = cmd.ValidateFlagGroups()
+			}
+			var files []string
+			var explicit bool
+			if err == nil {
+				files, explicit, err = runFiles(cmd, cmd.Flags().Args(), repoRoot)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("runFiles() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runFiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(files, tt.want) || explicit != tt.wantExplicit {
+				t.Errorf("runFiles() = %v, %v, want %v, %v", files, explicit, tt.want, tt.wantExplicit)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// currentSchemaVersion is the newest hook file schema this build understands.
+// Files without a schemaVersion are treated as version 1.
+const currentSchemaVersion = 1
+
+// schemaError is a problem found in a hook file, located by line and column.
+type schemaError struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e schemaError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Msg)
+	}
+	if e.Column == 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+}
+
+func nodeError(file string, node *yaml.Node, format string, args ...any) schemaError {
+	return schemaError{File: file, Line: node.Line, Column: node.Column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// decodeHooks strictly decodes a hook file, which holds either a list of hooks
+// under "hooks" or a single hook. Unknown keys are reported with their
+// position rather than silently ignored.
+func decodeHooks(file string, data []byte) ([]Hook, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, syntaxError(file, err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, schemaError{File: file, Msg: "file is empty"}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nodeError(file, root, "expected a mapping with either a hook or a list of hooks")
+	}
+
+	if err := checkSchemaVersion(file, root); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	var hooks []Hook
+	if mappingValue(root, "hooks") != nil {
+		checkKnownFields(file, root, reflect.TypeOf(OmniHook{}), "hook file", &errs)
+		var omniHook OmniHook
+		if err := root.Decode(&omniHook); err != nil {
+			errs = append(errs, decodeError(file, err))
+		}
+		hooks = omniHook.Hooks
+		if len(hooks) == 0 && len(errs) == 0 {
+			errs = append(errs, nodeError(file, mappingValue(root, "hooks"), "hooks must list at least one hook"))
+		}
+		if items := mappingValue(root, "hooks"); items != nil && items.Kind == yaml.SequenceNode {
+			for i := range hooks {
+				if i < len(items.Content) {
+					hooks[i].position = fmt.Sprintf("%s:%d:%d", file, items.Content[i].Line, items.Content[i].Column)
+				}
+			}
+		}
+	} else {
+		checkKnownFields(file, root, reflect.TypeOf(singleHookFile{}), "hook", &errs)
+		var single singleHookFile
+		if err := root.Decode(&single); err != nil {
+			errs = append(errs, decodeError(file, err))
+		}
+		single.Hook.position = fmt.Sprintf("%s:%d:%d", file, root.Line, root.Column)
+		hooks = []Hook{single.Hook}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return hooks, nil
+}
+
+// singleHookFile is the shape of a file defining one hook at the top level.
+type singleHookFile struct {
+	SchemaVersion int `yaml:"schemaVersion"`
+	Hook          `yaml:",inline"`
+}
+
+func checkSchemaVersion(file string, root *yaml.Node) error {
+	node := mappingValue(root, "schemaVersion")
+	if node == nil {
+		return nil
+	}
+	version, err := strconv.Atoi(node.Value)
+	if err != nil || node.Kind != yaml.ScalarNode || version < 1 {
+		return nodeError(file, node, "schemaVersion must be a positive integer")
+	}
+	if version > currentSchemaVersion {
+		return nodeError(file, node, "schemaVersion %d is newer than the supported version %d, upgrade omnihook", version, currentSchemaVersion)
+	}
+	return nil
+}
+
+// syntaxError locates a YAML syntax error, reported by yaml.v3 as
+// "yaml: line N: message".
+func syntaxError(file string, err error) error {
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	line, msg := splitLinePrefix(msg)
+	return schemaError{File: file, Line: line, Msg: msg}
+}
+
+// splitLinePrefix splits the "line N: " prefix off yaml.v3 error messages.
+func splitLinePrefix(msg string) (int, string) {
+	if rest, ok := strings.CutPrefix(msg, "line "); ok {
+		if number, tail, ok := strings.Cut(rest, ": "); ok {
+			if line, err := strconv.Atoi(number); err == nil {
+				return line, tail
+			}
+		}
+	}
+	return 0, msg
+}
+
+// decodeError converts a yaml.v3 decoding error, whose messages carry the
+// line but not the file, into schema errors.
+func decodeError(file string, err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return schemaError{File: file, Msg: err.Error()}
+	}
+	var errs []error
+	for _, msg := range typeErr.Errors {
+		line, msg := splitLinePrefix(msg)
+		errs = append(errs, schemaError{File: file, Line: line, Msg: msg})
+	}
+	return errors.Join(errs...)
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlFields maps the YAML keys of a struct type to their field types,
+// following inlined structs.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			for key, fieldType := range yamlFields(field.Type) {
+				fields[key] = fieldType
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// checkKnownFields reports keys in node that t has no field for, recursing
+// into nested structs, lists and maps of structs.
+func checkKnownFields(file string, node *yaml.Node, t reflect.Type, context string, errs *[]error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return // type mismatches are reported by Decode
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				msg := fmt.Sprintf("unknown field %q in %s", key.Value, context)
+				if suggestion := suggestField(key.Value, fields); suggestion != "" {
+					msg += fmt.Sprintf(", did you mean %q?", suggestion)
+				}
+				*errs = append(*errs, nodeError(file, key, "%s", msg))
+				continue
+			}
+			checkKnownFields(file, value, fieldType, key.Value, errs)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range node.Content {
+			checkKnownFields(file, item, t.Elem(), strings.TrimSuffix(context, "s"), errs)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkKnownFields(file, node.Content[i+1], t.Elem(), context+" entry", errs)
+		}
+	}
+}
+
+// suggestField returns the known field closest to an unknown key, catching
+// case mistakes and small typos.
+func suggestField(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+		if distance := editDistance(strings.ToLower(key), strings.ToLower(name)); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
+
+// schemaEnums lists the allowed values of fields whose type alone does not
+// constrain them.
+var schemaEnums = map[string][]string{
+	"hookType":  validHookTypes,
+	"hookTypes": validHookTypes,
+	"language":  validLanguages,
+	"builtin":   builtinNames(),
+}
+
+// schemaRequired lists the fields every hook definition must set.
+var schemaRequired = []string{"id", "name", "description"}
+
+// hooksJSONSchema generates the JSON Schema of hook files from the Go types
+// they are decoded into.
+func hooksJSONSchema() map[string]any {
+	hook := jsonSchemaFor(reflect.TypeOf(Hook{}))
+	hook["required"] = schemaRequired
+
+	single := jsonSchemaFor(reflect.TypeOf(singleHookFile{}))
+	single["required"] = schemaRequired
+
+	collection := jsonSchemaFor(reflect.TypeOf(OmniHook{}))
+	collection["required"] = []string{"hooks"}
+	collection["properties"].(map[string]any)["hooks"] = map[string]any{
+		"type":     "array",
+		"minItems": 1,
+		"items":    map[string]any{"$ref": "#/$defs/hook"},
+	}
+
+	return map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     "https://raw.githubusercontent.com/vjayajv/omnihook/main/schema/omnihook.schema.json",
+		"title":   "OmniHook hook file",
+		"oneOf":   []any{collection, single},
+		"$defs":   map[string]any{"hook": hook},
+	}
+}
+
+func jsonSchemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]any)
+		for name, fieldType := range yamlFields(t) {
+			property := jsonSchemaFor(fieldType)
+			if values, ok := schemaEnums[name]; ok && len(values) > 0 {
+				// A list takes the values for each of its items
+				if items, ok := property["items"].(map[string]any); ok {
+					items["enum"] = values
+				} else {
+					property["enum"] = values
+				}
+			}
+			if name == "schemaVersion" {
+				property["minimum"] = 1
+				property["maximum"] = currentSchemaVersion
+			}
+			properties[name] = property
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": jsonSchemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchemaFor(t.Elem())}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// TestSchemaUpToDate keeps the published schema in step with the Hook type,
+// which rejects any key it does not list.
+func TestSchemaUpToDate(t *testing.T) {
+	want, err := json.MarshalIndent(hooksJSONSchema(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, '\n')
+	got, err := os.ReadFile("../schema/omnihook.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("schema/omnihook.schema.json is out of date, run 'go generate ./cmd'")
+	}
+}
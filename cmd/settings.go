@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/vjayajv/omnihook/checks"
+	"gopkg.in/yaml.v3"
+)
+
+// repoConfigFile is the repo-level omnihook configuration, read from the root
+// of the repository hooks run in.
+const repoConfigFile = ".omnihook.yml"
+
+// HookSettings are the runtime parameters of a hook. Hook definitions set the
+// defaults, which user and repo-level config can override per hook ID.
+type HookSettings struct {
+	Args          []string          `yaml:"args,omitempty" json:"args,omitempty"`
+	Env           map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+	WorkingDir    string            `yaml:"workingDir,omitempty" json:"workingDir,omitempty"`
+	PassFilenames *bool             `yaml:"passFilenames,omitempty" json:"passFilenames,omitempty"`
+	// Options configure built-in checks.
+	Options checks.Options `yaml:"options,omitempty" json:"options,omitempty"`
+}
+
+// hookOverrides is the shape shared by the user and repo-level config files.
+type hookOverrides struct {
+	Hooks map[string]HookSettings `yaml:"hooks"`
+	// Required lists hook IDs that may not be skipped.
+	Required []string `yaml:"required"`
+	// Restage stages files hooks modify again, so that fixes made while
+	// committing are part of the commit.
+	Restage *bool `yaml:"restage"`
+}
+
+// merge returns s with every field set in override applied on top. Env and
+// Options are merged key by key; everything else is replaced.
+func (s HookSettings) merge(override HookSettings) HookSettings {
+	merged := s
+	if override.Args != nil {
+		merged.Args = override.Args
+	}
+	if len(override.Env) > 0 {
+		merged.Env = make(map[string]string, len(s.Env)+len(override.Env))
+		for key, value := range s.Env {
+			merged.Env[key] = value
+		}
+		for key, value := range override.Env {
+			merged.Env[key] = value
+		}
+	}
+	if len(override.Options) > 0 {
+		merged.Options = make(checks.Options, len(s.Options)+len(override.Options))
+		for key, value := range s.Options {
+			merged.Options[key] = value
+		}
+		for key, value := range override.Options {
+			merged.Options[key] = value
+		}
+	}
+	if override.WorkingDir != "" {
+		merged.WorkingDir = override.WorkingDir
+	}
+	if override.PassFilenames != nil {
+		merged.PassFilenames = override.PassFilenames
+	}
+	return merged
+}
+
+// passFilenames reports whether the files a hook runs against are appended to
+// its arguments.
+func (s HookSettings) passFilenames() bool {
+	return s.PassFilenames != nil && *s.PassFilenames
+}
+
+// environ returns the hook's environment variables as KEY=value pairs, with
+// references to the caller's environment expanded.
+func (s HookSettings) environ() []string {
+	keys := make([]string, 0, len(s.Env))
+	for key := range s.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, key+"="+os.ExpandEnv(s.Env[key]))
+	}
+	return env
+}
+
+// workingDir resolves the directory a hook runs in. Relative paths are taken
+// from the repository root, which is also the default.
+func (s HookSettings) workingDir(repoRoot string) string {
+	if s.WorkingDir == "" {
+		return repoRoot
+	}
+	if filepath.IsAbs(s.WorkingDir) || repoRoot == "" {
+		return s.WorkingDir
+	}
+	return filepath.Join(repoRoot, s.WorkingDir)
+}
+
+// overrideLayers holds the per-hook overrides from user and repo config, in
+// the order they are applied.
+type overrideLayers []map[string]HookSettings
+
+// loadOverrideLayers reads the user config followed by the repo-level config
+// of repoRoot, so repository settings win.
+func loadOverrideLayers(repoRoot string) (overrideLayers, error) {
+	var layers overrideLayers
+	// The config file is read directly rather than through viper, which
+	// lower-cases keys such as hook IDs and environment variable names.
+	if configFile := viper.ConfigFileUsed(); configFile != "" {
+		overrides, err := readHookOverrides(configFile)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, overrides.Hooks)
+	}
+	if repoRoot != "" {
+		overrides, err := readHookOverrides(filepath.Join(repoRoot, repoConfigFile))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, overrides.Hooks)
+	}
+	return layers, nil
+}
+
+// configPaths returns the user config followed by the repo-level config of
+// repoRoot.
+func configPaths(repoRoot string) []string {
+	var paths []string
+	if configFile := viper.ConfigFileUsed(); configFile != "" {
+		paths = append(paths, configFile)
+	}
+	if repoRoot != "" {
+		paths = append(paths, filepath.Join(repoRoot, repoConfigFile))
+	}
+	return paths
+}
+
+// loadRequiredHooks returns the hook IDs the user and repo-level config mark
+// as required. Either layer can only add to the list.
+func loadRequiredHooks(repoRoot string) ([]string, error) {
+	var required []string
+	for _, path := range configPaths(repoRoot) {
+		overrides, err := readHookOverrides(path)
+		if err != nil {
+			return nil, err
+		}
+		required = append(required, overrides.Required...)
+	}
+	return required, nil
+}
+
+// loadRestage reports whether files modified by hooks are staged again. The
+// repo-level config wins over the user config.
+func loadRestage(repoRoot string) (bool, error) {
+	restage := false
+	for _, path := range configPaths(repoRoot) {
+		overrides, err := readHookOverrides(path)
+		if err != nil {
+			return false, err
+		}
+		if overrides.Restage != nil {
+			restage = *overrides.Restage
+		}
+	}
+	return restage, nil
+}
+
+// matchesHookID reports whether pattern names the hook id, either exactly or
+// by the bare ID of a namespaced "<alias>/<id>".
+func matchesHookID(pattern, id string) bool {
+	return pattern == id || strings.HasSuffix(id, "/"+pattern)
+}
+
+func readHookOverrides(path string) (hookOverrides, error) {
+	var overrides hookOverrides
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return overrides, nil
+	}
+	if err != nil {
+		return overrides, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return overrides, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// apply returns the effective settings of a hook. Within each layer an entry
+// for the bare ID applies first, then one for the namespaced "<alias>/<id>".
+func (layers overrideLayers) apply(id string, settings HookSettings) HookSettings {
+	bareID := id
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		bareID = id[i+1:]
+	}
+	for _, layer := range layers {
+		if override, ok := layer[bareID]; ok && bareID != id {
+			settings = settings.merge(override)
+		}
+		if override, ok := layer[id]; ok {
+			settings = settings.merge(override)
+		}
+	}
+	return settings
+}
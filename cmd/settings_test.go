@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/vjayajv/omnihook/checks"
+)
+
+// writeConfigs points omnihook at a user config and returns a repository
+// root holding a repo-level config. Empty contents leave a file out.
+func writeConfigs(t *testing.T, user, repo string) string {
+	t.Helper()
+	dir := t.TempDir()
+	userFile := filepath.Join(dir, "config.yaml")
+	if user != "" {
+		if err := os.WriteFile(userFile, []byte(user), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	viper.SetConfigFile(userFile)
+	t.Cleanup(viper.Reset)
+
+	repoRoot := filepath.Join(dir, "repo")
+	if err := os.Mkdir(repoRoot, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if repo != "" {
+		if err := os.WriteFile(filepath.Join(repoRoot, repoConfigFile), []byte(repo), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repoRoot
+}
+
+func TestOverrideLayersApply(t *testing.T) {
+	defaults := HookSettings{Args: []string{"--default"}, Env: map[string]string{"A": "hook"}, Options: checks.Options{"maxSize": "1MB"}}
+	tests := []struct {
+		name       string
+		user, repo string
+		id         string
+		want       HookSettings
+	}{
+		{
+			name: "no overrides",
+			id:   "lint",
+			want: defaults,
+		},
+		{
+			name: "user config",
+			user: "hooks:\n  lint:\n    args: [--user]\n    env: {B: user}\n",
+			id:   "lint",
+			want: HookSettings{Args: []string{"--user"}, Env: map[string]string{"A": "hook", "B": "user"}, Options: checks.Options{"maxSize": "1MB"}},
+		},
+		{
+			name: "repo config wins over user config",
+			user: "hooks:\n  lint:\n    args: [--user]\n    env: {A: user, B: user}\n",
+			repo: "hooks:\n  lint:\n    args: [--repo]\n    env: {B: repo}\n",
+			id:   "lint",
+			want: HookSettings{Args: []string{"--repo"}, Env: map[string]string{"A": "user", "B": "repo"}, Options: checks.Options{"maxSize": "1MB"}},
+		},
+		{
+			name: "namespaced ID wins over bare ID",
+			user: "hooks:\n  team/lint:\n    args: [--namespaced]\n  lint:\n    args: [--bare]\n    workingDir: web\n",
+			id:   "team/lint",
+			want: HookSettings{Args: []string{"--namespaced"}, Env: map[string]string{"A": "hook"}, WorkingDir: "web", Options: checks.Options{"maxSize": "1MB"}},
+		},
+		{
+			name: "repo bare ID wins over user namespaced ID",
+			user: "hooks:\n  team/lint:\n    args: [--user]\n",
+			repo: "hooks:\n  lint:\n    args: [--repo]\n",
+			id:   "team/lint",
+			want: HookSettings{Args: []string{"--repo"}, Env: map[string]string{"A": "hook"}, Options: checks.Options{"maxSize": "1MB"}},
+		},
+		{
+			name: "bare ID of another hook",
+			user: "hooks:\n  other/lint:\n    args: [--other]\n",
+			id:   "team/lint",
+			want: defaults,
+		},
+		{
+			name: "options merged key by key",
+			repo: "hooks:\n  lint:\n    options: {exclude: [vendor/**]}\n",
+			id:   "lint",
+			want: HookSettings{Args: []string{"--default"}, Env: map[string]string{"A": "hook"}, Options: checks.Options{"maxSize": "1MB", "exclude": []any{"vendor/**"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := writeConfigs(t, tt.user, tt.repo)
+			layers, err := loadOverrideLayers(repoRoot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := layers.apply(tt.id, defaults); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("apply(%q) = %+v, want %+v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPassFilenames(t *testing.T) {
+	tests := []struct {
+		name       string
+		hook       *bool
+		user, repo string
+		want       bool
+	}{
+		{name: "off by default", want: false},
+		{name: "hook definition", hook: boolPtr(true), want: true},
+		{name: "user config turns it on", user: "hooks:\n  lint:\n    passFilenames: true\n", want: true},
+		{name: "repo config turns it off", hook: boolPtr(true), user: "hooks:\n  lint:\n    passFilenames: true\n", repo: "hooks:\n  lint:\n    passFilenames: false\n", want: false},
+		{name: "unset leaves the hook's", hook: boolPtr(true), repo: "hooks:\n  lint:\n    args: [-v]\n", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := writeConfigs(t, tt.user, tt.repo)
+			layers, err := loadOverrideLayers(repoRoot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := layers.apply("lint", HookSettings{PassFilenames: tt.hook}).passFilenames(); got != tt.want {
+				t.Errorf("passFilenames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
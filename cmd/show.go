@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vjayajv/omnihook/utils"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <[alias/]hook_id>",
+	Short: "Show the details of an installed hook",
+	Long: `Show what omnihook knows about an installed hook: its definition, where it
+was installed from, whether it is enabled for each hook type, the settings it
+runs with after user and repo-level overrides, and its script.`,
+	Args: cobra.ExactArgs(1),
+	RunE: showHook,
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+	showCmd.Flags().String("type", "", "Only show the hook as installed for this type")
+	showCmd.Flags().Bool("json", false, "Print the details as JSON")
+}
+
+// hookTypeState is whether a hook is enabled for one of its hook types.
+type hookTypeState struct {
+	HookType string `json:"hookType" yaml:"hookType"`
+	Enabled  bool   `json:"enabled" yaml:"enabled"`
+}
+
+// hookDetails is everything show reports about an installed hook.
+type hookDetails struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Language    string          `json:"language,omitempty"`
+	Builtin     string          `json:"builtin,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Types       []hookTypeState `json:"types"`
+	Source      string          `json:"source,omitempty"`
+	Ref         string          `json:"ref,omitempty"`
+	InstalledAt *time.Time      `json:"installedAt,omitempty"`
+	Checksum    string          `json:"checksum,omitempty"`
+	Modified    bool            `json:"modified"`
+	Settings    HookSettings    `json:"settings"`
+	Script      string          `json:"script"`
+}
+
+func showHook(cmd *cobra.Command, args []string) error {
+	hooksDir := viper.GetString("omni_hooks_dir")
+	if hooksDir == "" {
+		return fmt.Errorf("hooks directory not set. Run 'omnihook configure' first")
+	}
+
+	hookType, _ := cmd.Flags().GetString("type")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	details, err := inspectHook(hooksDir, hookType, args[0])
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(details)
+	}
+	printHookDetails(details)
+	return nil
+}
+
+// inspectHook gathers the details of a hook from its files under hooksDir, its
+// install records and the override layers of the current repository.
+func inspectHook(hooksDir, hookType, id string) (hookDetails, error) {
+	targets, err := hookTargets(hooksDir, hookType, id)
+	if err != nil {
+		return hookDetails{}, err
+	}
+	if len(targets) == 0 {
+		if hookType != "" {
+			return hookDetails{}, fmt.Errorf("hook '%s' is not installed for type '%s'", id, hookType)
+		}
+		return hookDetails{}, fmt.Errorf("hook '%s' does not exist", id)
+	}
+
+	cache, err := readCache()
+	if err != nil {
+		return hookDetails{}, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	details := hookDetails{ID: targets[0].ID}
+	var record InstalledHook
+	for _, target := range targets {
+		details.Types = append(details.Types, hookTypeState{HookType: target.HookType, Enabled: !target.Disabled})
+		if found, ok := cache.findHook(target.HookType, target.ID); ok && record.ID == "" {
+			record = found
+		}
+	}
+	sort.Slice(details.Types, func(i, j int) bool { return details.Types[i].HookType < details.Types[j].HookType })
+
+	details.Name = record.Name
+	details.Description = record.Description
+	details.Language = record.Language
+	details.Builtin = record.Builtin
+	details.Tags = record.Tags
+	details.Source = record.Source
+	details.Ref = record.Ref
+	details.Checksum = record.Checksum
+	if !record.InstalledAt.IsZero() {
+		details.InstalledAt = &record.InstalledAt
+	}
+
+	// Hooks installed before content was stored per hook only have the file
+	// under their type directory.
+	script, err := os.ReadFile(hookStoreFile(hooksDir, details.ID))
+	if os.IsNotExist(err) {
+		script, err = os.ReadFile(targets[0].Path)
+		if os.IsNotExist(err) {
+			script, err = os.ReadFile(targets[0].Path + ".disabled")
+		}
+	}
+	if err != nil {
+		return hookDetails{}, fmt.Errorf("failed to read hook '%s': %w", details.ID, err)
+	}
+	details.Script = string(script)
+	details.Modified = record.Checksum != "" && contentChecksum(script) != record.Checksum
+
+	repoRoot, _ := utils.GitRepoRoot()
+	layers, err := loadOverrideLayers(repoRoot)
+	if err != nil {
+		return hookDetails{}, err
+	}
+	details.Settings = layers.apply(details.ID, record.HookSettings)
+	return details, nil
+}
+
+func printHookDetails(details hookDetails) {
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Printf("%-13s %s\n", label+":", value)
+		}
+	}
+
+	types := make([]string, 0, len(details.Types))
+	for _, state := range details.Types {
+		status := "enabled"
+		if !state.Enabled {
+			status = "disabled"
+		}
+		types = append(types, fmt.Sprintf("%s (%s)", state.HookType, status))
+	}
+
+	field("ID", details.ID)
+	field("Name", details.Name)
+	field("Description", details.Description)
+	field("Language", details.Language)
+	field("Builtin", details.Builtin)
+	field("Tags", strings.Join(details.Tags, ", "))
+	field("Types", strings.Join(types, ", "))
+	field("Source", details.Source)
+	field("Ref", details.Ref)
+	if details.InstalledAt != nil {
+		field("Installed", details.InstalledAt.Local().Format(time.RFC3339))
+	}
+	if details.Checksum != "" {
+		checksum := "sha256:" + details.Checksum
+		if details.Modified {
+			checksum += " (modified since install)"
+		}
+		field("Checksum", checksum)
+	}
+
+	fmt.Println("Settings:")
+	settings := details.Settings
+	if len(settings.Args) == 0 && len(settings.Env) == 0 && settings.WorkingDir == "" && settings.PassFilenames == nil && len(settings.Options) == 0 {
+		fmt.Println("  (defaults)")
+	}
+	if len(settings.Args) > 0 {
+		fmt.Printf("  args:          %s\n", strings.Join(settings.Args, " "))
+	}
+	keys := make([]string, 0, len(settings.Env))
+	for key := range settings.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("  env:           %s=%s\n", key, settings.Env[key])
+	}
+	if settings.WorkingDir != "" {
+		fmt.Printf("  workingDir:    %s\n", settings.WorkingDir)
+	}
+	if settings.PassFilenames != nil {
+		fmt.Printf("  passFilenames: %t\n", *settings.PassFilenames)
+	}
+	options := make([]string, 0, len(settings.Options))
+	for key := range settings.Options {
+		options = append(options, key)
+	}
+	sort.Strings(options)
+	for _, key := range options {
+		fmt.Printf("  options:       %s=%v\n", key, settings.Options[key])
+	}
+
+	fmt.Println("Script:")
+	for _, line := range strings.Split(strings.TrimRight(details.Script, "\n"), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// httpClient is used for downloading hook archives and YAML files.
+var httpClient = &http.Client{Timeout: 2 * time.Minute}
+
+// fetchHooks loads hook definitions from whichever source opts points at.
+func fetchHooks(opts installOptions) ([]Hook, error) {
+	switch {
+	case opts.Dir != "":
+		if opts.Checksum != "" {
+			return nil, errors.New("--sha256 cannot be used with --dir")
+		}
+		return fetchHooksFromLocalDir(opts.Dir)
+	case opts.File != "":
+		if err := verifyFileChecksum(opts.File, opts.Checksum); err != nil {
+			return nil, err
+		}
+		if isArchive(opts.File) {
+			return fetchHooksFromArchive(opts.File)
+		}
+		return loadHooksFromFile(opts.File)
+	case isHTTPDownload(opts.URL):
+		return fetchHooksFromHTTP(opts.URL, opts.Checksum)
+	default:
+		if opts.Checksum != "" {
+			return nil, errors.New("--sha256 cannot be used with Git repositories")
+		}
+		return fetchHooksFromGitRepo(opts.URL, opts.Ref)
+	}
+}
+
+// sourceOptions picks how to load a source given as a local path or URL, such
+// as one recorded in the cache or the target of validate.
+func sourceOptions(source, checksum string) installOptions {
+	if info, err := os.Stat(source); err == nil {
+		if info.IsDir() {
+			return installOptions{Dir: source}
+		}
+		return installOptions{File: source, Checksum: checksum}
+	}
+	return installOptions{URL: source, Checksum: checksum}
+}
+
+func fetchHooksFromLocalDir(dir string) ([]Hook, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	hooks, err := fetchHooksFromDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(hooks) == 0 {
+		return nil, fmt.Errorf("no omnihook.yml files found in %s", dir)
+	}
+	return hooks, nil
+}
+
+func fetchHooksFromArchive(archivePath string) ([]Hook, error) {
+	tempDir, err := os.MkdirTemp("", "omnihook-archive-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := extractArchive(archivePath, tempDir); err != nil {
+		return nil, err
+	}
+
+	hooks, err := fetchHooksFromDir(tempDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(hooks) == 0 {
+		return nil, errors.New("no omnihook.yml files found in archive")
+	}
+	return hooks, nil
+}
+
+func fetchHooksFromHTTP(rawURL, checksum string) ([]Hook, error) {
+	tempDir, err := os.MkdirTemp("", "omnihook-download-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	downloadPath := filepath.Join(tempDir, path.Base(parsed.Path))
+	if err := downloadFile(rawURL, downloadPath, checksum); err != nil {
+		return nil, err
+	}
+
+	if isArchive(downloadPath) {
+		return fetchHooksFromArchive(downloadPath)
+	}
+	return loadHooksFromFile(downloadPath)
+}
+
+// downloadFile saves the body of rawURL to dest, verifying its SHA-256
+// checksum when one is pinned.
+func downloadFile(rawURL, dest, checksum string) error {
+	resp, err := httpClient.Get(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", rawURL, resp.Status)
+	}
+
+	file, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash), resp.Body); err != nil {
+		return fmt.Errorf("failed to download %s: %w", rawURL, err)
+	}
+
+	return compareChecksum(rawURL, hex.EncodeToString(hash.Sum(nil)), checksum)
+}
+
+func verifyFileChecksum(filePath, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return compareChecksum(filePath, hex.EncodeToString(hash.Sum(nil)), checksum)
+}
+
+// contentChecksum returns the hex encoded SHA-256 of data.
+func contentChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func compareChecksum(name, actual, expected string) error {
+	if expected == "" || strings.EqualFold(actual, expected) {
+		return nil
+	}
+	return fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", name, strings.ToLower(expected), actual)
+}
+
+// isHTTPDownload reports whether rawURL points at an archive or YAML file
+// served over HTTP(S) rather than at a Git repository.
+func isHTTPDownload(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+	return isArchive(parsed.Path) || isYAMLFile(parsed.Path)
+}
+
+func isArchive(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz") || strings.HasSuffix(name, ".zip")
+}
+
+func isYAMLFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+func extractArchive(archivePath, dest string) error {
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		return extractZip(archivePath, dest)
+	}
+	return extractTarGz(archivePath, dest)
+}
+
+// archiveEntryPath resolves an archive member against dest, refusing entries
+// that would escape it.
+func archiveEntryPath(dest, name string) (string, error) {
+	target := filepath.Join(dest, filepath.FromSlash(name))
+	if target != dest && !strings.HasPrefix(target, dest+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %s escapes the extraction directory", name)
+	}
+	return target, nil
+}
+
+func extractZip(archivePath, dest string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer reader.Close()
+
+	for _, entry := range reader.File {
+		target, err := archiveEntryPath(dest, entry.Name)
+		if err != nil {
+			return err
+		}
+		if entry.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to extract %s: %w", entry.Name, err)
+			}
+			continue
+		}
+		if !entry.Mode().IsRegular() {
+			continue // symlinks and other special files are not extracted
+		}
+
+		src, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", entry.Name, err)
+		}
+		err = writeExtractedFile(target, src, entry.Mode())
+		src.Close()
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", entry.Name, err)
+		}
+	}
+	return nil
+}
+
+func extractTarGz(archivePath, dest string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		target, err := archiveEntryPath(dest, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+			}
+		case tar.TypeReg:
+			if err := writeExtractedFile(target, reader, header.FileInfo().Mode()); err != nil {
+				return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+			}
+		}
+	}
+}
+
+func writeExtractedFile(target string, src io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
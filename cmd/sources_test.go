@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testHookFile = `id: lint
+name: Lint
+description: Runs the linter
+script: echo lint
+`
+
+// archiveEntry is a member of an archive built by a test. Link makes it a
+// symbolic link, and a name ending in / a directory.
+type archiveEntry struct {
+	Name string
+	Body string
+	Link string
+}
+
+func buildZip(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.Name, Method: zip.Deflate}
+		body := entry.Body
+		switch {
+		case entry.Link != "":
+			header.SetMode(os.ModeSymlink | 0777)
+			body = entry.Link
+		case strings.HasSuffix(entry.Name, "/"):
+			header.SetMode(os.ModeDir | 0755)
+		default:
+			header.SetMode(0644)
+		}
+		w, err := writer.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildTarGz(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	writer := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.Name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(entry.Body))}
+		switch {
+		case entry.Link != "":
+			header.Typeflag, header.Linkname, header.Size = tar.TypeSymlink, entry.Link, 0
+		case strings.HasSuffix(entry.Name, "/"):
+			header.Typeflag, header.Mode, header.Size = tar.TypeDir, 0755, 0
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := writer.Write([]byte(entry.Body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// serveFiles serves each file at its path, and 404 for anything else.
+func serveFiles(t *testing.T, files map[string][]byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchHooksFromHTTP(t *testing.T) {
+	entries := []archiveEntry{
+		{Name: "hooks/"},
+		{Name: "hooks/lint/omnihook.yml", Body: testHookFile},
+		{Name: "hooks/README.md", Body: "docs"},
+	}
+	server := serveFiles(t, map[string][]byte{
+		"/hooks.zip":    buildZip(t, entries),
+		"/hooks.tar.gz": buildTarGz(t, entries),
+		"/hooks.tgz":    buildTarGz(t, entries),
+		"/omnihook.yml": []byte(testHookFile),
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "zip archive", path: "/hooks.zip"},
+		{name: "tar.gz archive", path: "/hooks.tar.gz"},
+		{name: "tgz archive", path: "/hooks.tgz"},
+		{name: "raw YAML file", path: "/omnihook.yml"},
+		{name: "missing file", path: "/missing.zip", wantErr: "404 Not Found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := installOptions{URL: server.URL + tt.path}
+			if !isHTTPDownload(opts.URL) {
+				t.Fatalf("isHTTPDownload(%q) = false, want true", opts.URL)
+			}
+			hooks, err := fetchHooks(opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("fetchHooks() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fetchHooks() error = %v", err)
+			}
+			if len(hooks) != 1 || hooks[0].ID != "lint" || hooks[0].Script != "echo lint" {
+				t.Fatalf("fetchHooks() = %+v, want the lint hook", hooks)
+			}
+		})
+	}
+}
+
+func TestFetchHooksChecksum(t *testing.T) {
+	data := []byte(testHookFile)
+	server := serveFiles(t, map[string][]byte{"/omnihook.yml": data})
+	checksum := contentChecksum(data)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "omnihook.yml")
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    installOptions
+		wantErr string
+	}{
+		{name: "HTTP match", opts: installOptions{URL: server.URL + "/omnihook.yml", Checksum: checksum}},
+		{name: "HTTP match ignores case", opts: installOptions{URL: server.URL + "/omnihook.yml", Checksum: strings.ToUpper(checksum)}},
+		{name: "HTTP mismatch", opts: installOptions{URL: server.URL + "/omnihook.yml", Checksum: strings.Repeat("0", 64)}, wantErr: "checksum mismatch"},
+		{name: "file match", opts: installOptions{File: file, Checksum: checksum}},
+		{name: "file mismatch", opts: installOptions{File: file, Checksum: strings.Repeat("0", 64)}, wantErr: "checksum mismatch"},
+		{name: "directory", opts: installOptions{Dir: dir, Checksum: checksum}, wantErr: "cannot be used with --dir"},
+		{name: "Git repository", opts: installOptions{URL: "https://example.com/hooks.git", Checksum: checksum}, wantErr: "cannot be used with Git repositories"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fetchHooks(tt.opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("fetchHooks() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("fetchHooks() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	builders := map[string]func(*testing.T, []archiveEntry) []byte{
+		"hooks.zip":    buildZip,
+		"hooks.tar.gz": buildTarGz,
+	}
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+		// want lists the files expected after extraction; missing lists
+		// those that must not exist.
+		want    []string
+		missing []string
+		wantErr string
+	}{
+		{
+			name:    "nested files",
+			entries: []archiveEntry{{Name: "a/omnihook.yml", Body: testHookFile}, {Name: "a/b/lib.sh", Body: "true"}},
+			want:    []string{"a/omnihook.yml", "a/b/lib.sh"},
+		},
+		{
+			name:    "parent directory traversal",
+			entries: []archiveEntry{{Name: "../escaped.sh", Body: "rm -rf /"}},
+			wantErr: "escapes the extraction directory",
+		},
+		{
+			name:    "nested traversal",
+			entries: []archiveEntry{{Name: "a/../../escaped.sh", Body: "rm -rf /"}},
+			wantErr: "escapes the extraction directory",
+		},
+		{
+			name:    "symbolic links are skipped",
+			entries: []archiveEntry{{Name: "passwd", Link: "/etc/passwd"}, {Name: "omnihook.yml", Body: testHookFile}},
+			want:    []string{"omnihook.yml"},
+			missing: []string{"passwd"},
+		},
+	}
+	for archive, build := range builders {
+		for _, tt := range tests {
+			t.Run(archive+"/"+tt.name, func(t *testing.T) {
+				dir := t.TempDir()
+				archivePath := filepath.Join(dir, archive)
+				if err := os.WriteFile(archivePath, build(t, tt.entries), 0644); err != nil {
+					t.Fatal(err)
+				}
+				dest := filepath.Join(dir, "out")
+				if err := os.Mkdir(dest, 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				err := extractArchive(archivePath, dest)
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("extractArchive() error = %v, want one containing %q", err, tt.wantErr)
+					}
+					if _, err := os.Lstat(filepath.Join(dir, "escaped.sh")); !os.IsNotExist(err) {
+						t.Fatalf("entry was written outside the extraction directory")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("extractArchive() error = %v", err)
+				}
+				for _, name := range tt.want {
+					if info, err := os.Lstat(filepath.Join(dest, name)); err != nil || !info.Mode().IsRegular() {
+						t.Errorf("%s was not extracted as a regular file: %v", name, err)
+					}
+				}
+				for _, name := range tt.missing {
+					if _, err := os.Lstat(filepath.Join(dest, name)); !os.IsNotExist(err) {
+						t.Errorf("%s was extracted, want it skipped", name)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSourceOptions(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "omnihook.yml")
+	if err := os.WriteFile(file, []byte(testHookFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		source string
+		want   installOptions
+	}{
+		{source: dir, want: installOptions{Dir: dir}},
+		{source: file, want: installOptions{File: file, Checksum: "abc"}},
+		{source: "https://example.com/hooks.git", want: installOptions{URL: "https://example.com/hooks.git", Checksum: "abc"}},
+	}
+	for _, tt := range tests {
+		if got := sourceOptions(tt.source, "abc"); got != tt.want {
+			t.Errorf("sourceOptions(%q) = %+v, want %+v", tt.source, got, tt.want)
+		}
+	}
+}
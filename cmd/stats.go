@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jwalton/gchalk"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show how long hooks take and how often they fail",
+	Long: `Show per-hook statistics from the run history: how often each hook ran, its
+median and 95th percentile duration, and how often it failed or was skipped.
+
+Hooks run in parallel, so a run takes as long as its slowest hook. The SLOWEST
+column shows how often each hook was the one holding up the run; hooks that
+hold it up most often are highlighted.`,
+	RunE: showStats,
+}
+
+func init() {
+	statsCmd.Flags().Duration("since", 30*24*time.Hour, "Only include runs within this window")
+	statsCmd.Flags().String("repo", "", "Only include runs in this repository")
+	statsCmd.Flags().String("type", "", "Only include hooks of this type")
+	statsCmd.Flags().String("output", "table", "Output format: table or json")
+	rootCmd.AddCommand(statsCmd)
+}
+
+// hookStats summarizes the history of one hook.
+type hookStats struct {
+	ID          string        `json:"id"`
+	HookType    string        `json:"hookType"`
+	Runs        int           `json:"runs"`
+	Failures    int           `json:"failures"`
+	Skips       int           `json:"skips"`
+	P50         time.Duration `json:"p50"`
+	P95         time.Duration `json:"p95"`
+	FailureRate float64       `json:"failureRate"`
+	SkipRate    float64       `json:"skipRate"`
+	// SlowestRate is the share of runs in which this hook finished last.
+	SlowestRate float64 `json:"slowestRate"`
+
+	durations []time.Duration
+	slowest   int
+}
+
+// runStats summarizes the runs the hook statistics were taken from.
+type runStats struct {
+	Since time.Time     `json:"since"`
+	Runs  int           `json:"runs"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	Hooks []*hookStats  `json:"hooks"`
+}
+
+func showStats(cmd *cobra.Command, args []string) error {
+	since, _ := cmd.Flags().GetDuration("since")
+	repo, _ := cmd.Flags().GetString("repo")
+	hookType, _ := cmd.Flags().GetString("type")
+	output, _ := cmd.Flags().GetString("output")
+	if output != "table" && output != "json" {
+		return fmt.Errorf("invalid output format '%s' (expected table or json)", output)
+	}
+	if repo != "" {
+		if abs, err := filepath.Abs(repo); err == nil {
+			repo = abs
+		}
+	}
+
+	runs, err := readHistory()
+	if err != nil {
+		return err
+	}
+
+	stats := summarizeRuns(runs, time.Now().Add(-since).UTC(), repo, hookType)
+
+	if output == "json" {
+		if stats.Hooks == nil {
+			stats.Hooks = []*hookStats{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	if stats.Runs == 0 {
+		fmt.Println("No hook runs recorded in this window.")
+		return nil
+	}
+	fmt.Printf("%d run(s) since %s, p50 %s, p95 %s\n\n", stats.Runs, stats.Since.Local().Format("2006-01-02 15:04"), stats.P50, stats.P95)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOOK\tTYPE\tRUNS\tP50\tP95\tFAILED\tSKIPPED\tSLOWEST")
+	highest := 0
+	for _, s := range stats.Hooks {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%.0f%%\t%.0f%%\t%.0f%%\n", s.ID, s.HookType, s.Runs, s.P50, s.P95,
+			s.FailureRate*100, s.SkipRate*100, s.SlowestRate*100)
+		highest = max(highest, s.slowest)
+	}
+	w.Flush()
+
+	// Highlight the hooks holding up the most runs
+	if highest > 0 {
+		fmt.Println()
+		for _, s := range stats.Hooks {
+			if s.slowest == highest {
+				fmt.Printf("%s %s (%s) held up %d of %d run(s)\n", gchalk.Yellow("⚠"), gchalk.Bold(s.ID), s.HookType, s.slowest, stats.Runs)
+			}
+		}
+	}
+	return nil
+}
+
+// summarizeRuns gathers the statistics of the runs since the given time,
+// keeping only those in repo and hooks of hookType when they are set.
+func summarizeRuns(runs []historyRun, since time.Time, repo, hookType string) runStats {
+	stats := runStats{Since: since}
+	byHook := make(map[string]*hookStats)
+	var runDurations []time.Duration
+	for _, run := range runs {
+		if run.Time.Before(stats.Since) || repo != "" && run.Repo != repo {
+			continue
+		}
+
+		var slowest *hookStats
+		var slowestDuration time.Duration
+		var wallClock time.Duration
+		counted := false
+		for _, hook := range run.Hooks {
+			if hookType != "" && hook.HookType != hookType {
+				continue
+			}
+			key := hook.HookType + "\x00" + hook.ID
+			s, ok := byHook[key]
+			if !ok {
+				s = &hookStats{ID: hook.ID, HookType: hook.HookType}
+				byHook[key] = s
+				stats.Hooks = append(stats.Hooks, s)
+			}
+			counted = true
+			s.Runs++
+			switch hook.Status {
+			case resultSkipped:
+				s.Skips++
+				continue
+			case resultFailed:
+				s.Failures++
+			}
+			s.durations = append(s.durations, hook.Duration)
+			wallClock = max(wallClock, hook.Duration)
+			if hook.Duration > slowestDuration {
+				slowest, slowestDuration = s, hook.Duration
+			}
+		}
+		if !counted {
+			continue
+		}
+		stats.Runs++
+		runDurations = append(runDurations, wallClock)
+		if slowest != nil {
+			slowest.slowest++
+		}
+	}
+
+	stats.P50, stats.P95 = percentile(runDurations, 50), percentile(runDurations, 95)
+	for _, s := range stats.Hooks {
+		s.P50, s.P95 = percentile(s.durations, 50), percentile(s.durations, 95)
+		// Skipped runs can neither fail nor hold up the run
+		s.FailureRate = rate(s.Failures, s.Runs-s.Skips)
+		s.SkipRate = rate(s.Skips, s.Runs)
+		s.SlowestRate = rate(s.slowest, s.Runs-s.Skips)
+	}
+	// The hooks that make commits slow come first
+	sort.SliceStable(stats.Hooks, func(i, j int) bool {
+		if stats.Hooks[i].P95 != stats.Hooks[j].P95 {
+			return stats.Hooks[i].P95 > stats.Hooks[j].P95
+		}
+		return stats.Hooks[i].ID < stats.Hooks[j].ID
+	})
+	return stats
+}
+
+// percentile returns the p-th percentile of durations by the nearest-rank
+// method.
+func percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+func rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
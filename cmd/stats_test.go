@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	ms := func(values ...int) []time.Duration {
+		var durations []time.Duration
+		for _, v := range values {
+			durations = append(durations, time.Duration(v)*time.Millisecond)
+		}
+		return durations
+	}
+	var twenty []int
+	for i := 20; i >= 1; i-- {
+		twenty = append(twenty, i)
+	}
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		p50, p95  time.Duration
+	}{
+		{name: "none"},
+		{name: "one", durations: ms(7), p50: 7 * time.Millisecond, p95: 7 * time.Millisecond},
+		{name: "two", durations: ms(9, 3), p50: 3 * time.Millisecond, p95: 9 * time.Millisecond},
+		{name: "twenty", durations: ms(twenty...), p50: 10 * time.Millisecond, p95: 19 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.durations, 50); got != tt.p50 {
+				t.Errorf("percentile(50) = %s, want %s", got, tt.p50)
+			}
+			if got := percentile(tt.durations, 95); got != tt.p95 {
+				t.Errorf("percentile(95) = %s, want %s", got, tt.p95)
+			}
+		})
+	}
+}
+
+func TestSummarizeRunsRates(t *testing.T) {
+	now := time.Now().UTC()
+	hook := func(id, status string, duration time.Duration) historyHook {
+		return historyHook{ID: id, HookType: "pre-commit", Status: status, Duration: duration}
+	}
+	runs := []historyRun{
+		{Time: now, Hooks: []historyHook{hook("lint", resultFailed, 3*time.Second), hook("fmt", resultPassed, time.Second)}},
+		{Time: now, Hooks: []historyHook{hook("lint", resultSkipped, 0), hook("fmt", resultPassed, 2*time.Second)}},
+		{Time: now, Hooks: []historyHook{hook("lint", resultSkipped, 0), hook("fmt", resultFailed, time.Second)}},
+		{Time: now, Hooks: []historyHook{hook("lint", resultSkipped, 0), hook("fmt", resultPassed, time.Second)}},
+		// Too old to count
+		{Time: now.Add(-48 * time.Hour), Hooks: []historyHook{hook("lint", resultFailed, time.Second)}},
+		// Only skipped
+		{Time: now, Hooks: []historyHook{hook("docs", resultSkipped, 0)}},
+	}
+
+	stats := summarizeRuns(runs, now.Add(-time.Hour), "", "")
+	if stats.Runs != 5 {
+		t.Errorf("Runs = %d, want 5", stats.Runs)
+	}
+	want := map[string]struct{ failure, skip, slowest float64 }{
+		// lint failed the one time it ran, so skips must not dilute that
+		"lint": {failure: 1, skip: 0.75, slowest: 1},
+		"fmt":  {failure: 0.25, skip: 0, slowest: 0.75},
+		"docs": {failure: 0, skip: 1, slowest: 0},
+	}
+	if len(stats.Hooks) != len(want) {
+		t.Fatalf("Hooks = %d, want %d", len(stats.Hooks), len(want))
+	}
+	for _, s := range stats.Hooks {
+		w, ok := want[s.ID]
+		if !ok {
+			t.Errorf("unexpected hook %s", s.ID)
+			continue
+		}
+		if s.FailureRate != w.failure || s.SkipRate != w.skip || s.SlowestRate != w.slowest {
+			t.Errorf("%s rates = failure %v, skip %v, slowest %v, want %v, %v, %v",
+				s.ID, s.FailureRate, s.SkipRate, s.SlowestRate, w.failure, w.skip, w.slowest)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -19,7 +20,7 @@ var uninstallCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(uninstallCmd)
-	uninstallCmd.Flags().String("id", "", "ID of the hook to uninstall")
+	uninstallCmd.Flags().String("id", "", "ID of the hook to uninstall, as <id> or <alias>/<id>; removed from every type unless --type is given")
 	uninstallCmd.Flags().Bool("all", false, "Remove all installed hooks")
 	uninstallCmd.Flags().String("type", "", "Remove all installed hooks of a specific type")
 }
@@ -38,12 +39,8 @@ func uninstallHook(cmd *cobra.Command, args []string) error {
 		return uninstallAllHooks(hooksDir)
 	}
 
-	if hookID != "" && hookType == "" {
-		return fmt.Errorf("--type is required when --id is specified")
-	}
-
-	if hookType == "" && !removeAll {
-		return fmt.Errorf("either --type, --id (with --type), or --all must be specified")
+	if hookType == "" && hookID == "" {
+		return fmt.Errorf("either --type, --id, or --all must be specified")
 	}
 
 	if hookID != "" {
@@ -80,11 +77,18 @@ func uninstallAllHooks(hooksDir string) error {
 		}
 	}
 
+	if err := forgetInstalledHooks(hooksDir, func(InstalledHook) bool { return true }); err != nil {
+		return err
+	}
+
 	fmt.Println("All hooks have been removed.")
 	return nil
 }
 
 func uninstallHookType(hooksDir, hookType string) error {
+	if err := checkHookTypeFlag(hookType); err != nil {
+		return err
+	}
 	typeDir := filepath.Join(hooksDir, hookType)
 	if _, err := os.Stat(typeDir); os.IsNotExist(err) {
 		return fmt.Errorf("hook type directory '%s' not found", hookType)
@@ -99,36 +103,87 @@ func uninstallHookType(hooksDir, hookType string) error {
 		return fmt.Errorf("failed to remove hook type directory '%s': %w", hookType, err)
 	}
 
+	if err := forgetInstalledHooks(hooksDir, func(hook InstalledHook) bool { return hook.HookType == hookType }); err != nil {
+		return err
+	}
+
 	fmt.Printf("All hooks of type '%s' have been removed.\n", hookType)
 	return nil
 }
 
+// uninstallSingleHook removes a hook from hookType, or from every type it is
+// installed for when hookType is empty.
 func uninstallSingleHook(hooksDir, hookType, hookID string) error {
-	hookPath := filepath.Join(hooksDir, hookType, hookID)
-	disabledHookPath := hookPath + ".disabled"
-
-	var targetPath string
-	if _, err := os.Stat(hookPath); !os.IsNotExist(err) {
-		targetPath = hookPath
-	} else if _, err := os.Stat(disabledHookPath); !os.IsNotExist(err) {
-		targetPath = disabledHookPath
-	} else {
+	targets, err := hookTargets(hooksDir, hookType, hookID)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		if hookType == "" {
+			return fmt.Errorf("hook '%s' not found", hookID)
+		}
 		return fmt.Errorf("hook '%s' of type '%s' not found", hookID, hookType)
 	}
 
-	if !confirmAction(fmt.Sprintf("Are you sure you want to remove hook '%s' of type '%s'? (y/N): ", hookID, hookType)) {
+	var types []string
+	for _, target := range targets {
+		types = append(types, target.HookType)
+	}
+	typeList := strings.Join(types, "', '")
+	hookID = targets[0].ID
+
+	if !confirmAction(fmt.Sprintf("Are you sure you want to remove hook '%s' of type '%s'? (y/N): ", hookID, typeList)) {
 		fmt.Println("Uninstall cancelled.")
 		return nil
 	}
 
-	if err := os.Remove(targetPath); err != nil {
-		return fmt.Errorf("failed to remove hook '%s': %w", hookID, err)
+	for _, target := range targets {
+		targetPath := target.Path
+		if target.Disabled {
+			targetPath += ".disabled"
+		}
+		if err := os.Remove(targetPath); err != nil {
+			return fmt.Errorf("failed to remove hook '%s': %w", target.ID, err)
+		}
+		// Drop the alias directory once its last hook is gone
+		if strings.Contains(target.ID, "/") {
+			os.Remove(filepath.Dir(targetPath))
+		}
+	}
+	if err := forgetInstalledHooks(hooksDir, func(hook InstalledHook) bool {
+		return slices.ContainsFunc(targets, func(target installedHookFile) bool {
+			return target.HookType == hook.HookType && target.ID == hook.ID
+		})
+	}); err != nil {
+		return err
 	}
 
-	fmt.Printf("Hook '%s' of type '%s' has been removed.\n", hookID, hookType)
+	fmt.Printf("Hook '%s' of type '%s' has been removed.\n", hookID, typeList)
 	return nil
 }
 
+// forgetInstalledHooks removes the cache records of uninstalled hooks, and the
+// directories of hooks no longer installed under any type.
+func forgetInstalledHooks(hooksDir string, match func(InstalledHook) bool) error {
+	cache, err := readCache()
+	if err != nil {
+		return err
+	}
+	var removed []string
+	for _, hook := range cache.Hooks {
+		if match(hook) {
+			removed = append(removed, hook.ID)
+		}
+	}
+	cache.forgetHooks(match)
+	for _, id := range removed {
+		if !cache.hasHookID(id) {
+			removeHookStore(hooksDir, id)
+		}
+	}
+	return writeCache(cache)
+}
+
 func confirmAction(message string) bool {
 	fmt.Print(message)
 	reader := bufio.NewReader(os.Stdin)
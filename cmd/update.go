@@ -4,10 +4,111 @@ import (
 	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
+	"time"
 )
 
 type Cache struct {
-	Sources []string `yaml:"sources"`
+	Sources   []string          `yaml:"sources"`
+	Aliases   map[string]string `yaml:"aliases,omitempty"`
+	Checksums map[string]string `yaml:"checksums,omitempty"`
+	Hooks     []InstalledHook   `yaml:"hooks,omitempty"`
+}
+
+// InstalledHook records which source a hook was installed from, along with
+// the settings the runner needs from its definition.
+type InstalledHook struct {
+	ID          string     `yaml:"id"`
+	HookType    string     `yaml:"hookType"`
+	Source      string     `yaml:"source"`
+	Ref         string     `yaml:"ref,omitempty"`
+	Name        string     `yaml:"name,omitempty"`
+	Description string     `yaml:"description,omitempty"`
+	Language    string     `yaml:"language,omitempty"`
+	Entry       string     `yaml:"entry,omitempty"`
+	Builtin     string     `yaml:"builtin,omitempty"`
+	InstalledAt time.Time  `yaml:"installedAt,omitempty"`
+	Checksum    string     `yaml:"checksum,omitempty"`
+	Tags        []string   `yaml:"tags,omitempty"`
+	Required    bool       `yaml:"required,omitempty"`
+	LastResult  *RunResult `yaml:"lastResult,omitempty"`
+
+	HookSettings `yaml:",inline"`
+}
+
+// RunResult is the outcome of the last time a hook ran.
+type RunResult struct {
+	Status   string        `yaml:"status" json:"status"`
+	At       time.Time     `yaml:"at" json:"at"`
+	Duration time.Duration `yaml:"duration" json:"duration"`
+}
+
+// Run result statuses
+const (
+	resultPassed = "passed"
+	resultFailed = "failed"
+)
+
+// aliasForSource returns the alias registered for a source, if any.
+func (c Cache) aliasForSource(source string) string {
+	for alias, src := range c.Aliases {
+		if src == source {
+			return alias
+		}
+	}
+	return ""
+}
+
+// findHook returns the install record for a hook, if any.
+func (c Cache) findHook(hookType, id string) (InstalledHook, bool) {
+	for _, hook := range c.Hooks {
+		if hook.HookType == hookType && hook.ID == id {
+			return hook, true
+		}
+	}
+	return InstalledHook{}, false
+}
+
+// hasHookID reports whether a hook ID is installed under any type.
+func (c Cache) hasHookID(id string) bool {
+	for _, hook := range c.Hooks {
+		if hook.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// recordHook adds or replaces the install record for a hook.
+func (c *Cache) recordHook(record InstalledHook) {
+	for i, hook := range c.Hooks {
+		if hook.HookType == record.HookType && hook.ID == record.ID {
+			c.Hooks[i] = record
+			return
+		}
+	}
+	c.Hooks = append(c.Hooks, record)
+}
+
+// recordResult stores the outcome of a hook's latest run on its install
+// record. Hooks without a record, such as ones copied in by hand, are skipped.
+func (c *Cache) recordResult(hookType, id string, result RunResult) {
+	for i, hook := range c.Hooks {
+		if hook.HookType == hookType && hook.ID == id {
+			c.Hooks[i].LastResult = &result
+			return
+		}
+	}
+}
+
+// forgetHooks drops the install records matching the given filter.
+func (c *Cache) forgetHooks(match func(InstalledHook) bool) {
+	kept := c.Hooks[:0]
+	for _, hook := range c.Hooks {
+		if !match(hook) {
+			kept = append(kept, hook)
+		}
+	}
+	c.Hooks = kept
 }
 
 var updateCmd = &cobra.Command{
@@ -32,22 +133,26 @@ func updateHooks(cmd *cobra.Command, args []string) error {
 	}
 
 	if url != "" {
-		return reinstallHooks([]string{url})
+		return reinstallHooks(cache, []string{url})
 	} else if all || len(args) == 0 {
 		if cache.Sources == nil {
 			return errors.New("no sources to update, use --url instead")
 		}
-		return reinstallHooks(cache.Sources)
+		return reinstallHooks(cache, cache.Sources)
 	}
 
 	return errors.New("invalid update parameters")
 }
 
-func reinstallHooks(sources []string) error {
+func reinstallHooks(cache Cache, sources []string) error {
+	var errs []error
 	for _, src := range sources {
 		fmt.Printf("Updating hooks from: %s\n", src)
 		// Call install logic for each stored source
-		installHook(src, "")
+		if err := installHook(sourceOptions(src, cache.Checksums[src])); err != nil {
+			fmt.Printf("Failed to update hooks from %s: %v\n", src, err)
+			errs = append(errs, fmt.Errorf("failed to update hooks from %s: %w", src, err))
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jwalton/gchalk"
+	"github.com/spf13/cobra"
+)
+
+//go:generate go run .. schema --output ../schema/omnihook.schema.json
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <file|dir|url>",
+	Short: "Validate hook definitions without installing them",
+	Long: `Validate hook definitions without installing them. Accepts the same sources
+as install: a hook YAML file, an archive, a directory containing omnihook.yml
+files, or a Git or HTTP(S) URL.`,
+	Args: cobra.ExactArgs(1),
+	RunE: validateHooks,
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of hook files",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+
+		data, err := json.MarshalIndent(hooksJSONSchema(), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to generate schema: %w", err)
+		}
+		data = append(data, '\n')
+
+		if output == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		return os.WriteFile(output, data, 0644)
+	},
+}
+
+func init() {
+	schemaCmd.Flags().String("output", "", "Write the schema to a file instead of stdout")
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func validateHooks(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	target := args[0]
+
+	hooks, err := fetchHooks(sourceOptions(target, ""))
+	if err != nil {
+		fmt.Println(gchalk.Red(err.Error()))
+		return errors.New("validation failed")
+	}
+
+	var errs []error
+	if err := checkCollisions(Cache{}, "", hooks, "", target, true); err != nil {
+		errs = append(errs, err)
+	}
+	for _, hook := range hooks {
+		if err := validateHook(hook); err != nil {
+			errs = append(errs, fmt.Errorf("%s: hook '%s': %w", hook.position, hook.ID, err))
+		}
+	}
+	if len(errs) > 0 {
+		fmt.Println(gchalk.Red(errors.Join(errs...).Error()))
+		return errors.New("validation failed")
+	}
+
+	fmt.Printf("✅ %d hook(s) in %s are valid\n", len(hooks), target)
+	return nil
+}
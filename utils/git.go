@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GitRepoRoot returns the top-level directory of the repository containing
+// the current working directory.
+func GitRepoRoot() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("not inside a git repository: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitHeadCommit returns the commit HEAD points at, or an empty string in a
+// repository without commits.
+func GitHeadCommit() string {
+	out, err := exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// GitCurrentBranch returns the name of the checked out branch, or an empty
+// string when HEAD is detached.
+func GitCurrentBranch() string {
+	out, err := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// GitStagedFiles returns the paths of files added, copied, modified or
+// renamed in the index, relative to the repository root.
+func GitStagedFiles() ([]string, error) {
+	return gitFileList("diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+}
+
+// GitUnstagedFiles returns the paths of files whose working tree copy
+// differs from the index, relative to the repository root.
+func GitUnstagedFiles() ([]string, error) {
+	return gitFileList("diff", "--name-only", "-z")
+}
+
+// GitAdd stages files given relative to the repository root.
+func GitAdd(repoRoot string, files []string) error {
+	out, err := exec.Command("git", append([]string{"-C", repoRoot, "add", "--"}, files...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git add failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// GitTrackedFiles returns every file tracked in the index, relative to the
+// repository root.
+func GitTrackedFiles() ([]string, error) {
+	return gitFileList("ls-files", "-z", "--full-name", ":/")
+}
+
+// GitChangedFiles returns the files added, copied, modified or renamed on
+// toRef since it diverged from fromRef, the way a pull request diff does.
+func GitChangedFiles(fromRef, toRef string) ([]string, error) {
+	return gitFileList("diff", "--name-only", "--diff-filter=ACMR", "-z", fromRef+"..."+toRef)
+}
+
+// gitFileList runs a git command printing NUL separated paths.
+func gitFileList(args ...string) ([]string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	var files []string
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}